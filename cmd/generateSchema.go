@@ -19,7 +19,7 @@ func generateSchemaFromMap(envMap map[string]string) map[string]validator.Schema
 
 	for key, value := range envMap {
 		schema[key] = validator.SchemaRule{
-			Type:     utils.GuessType(value),
+			Type:     utils.GuessKeyType(key, value),
 			Required: false,
 			Default:  value,
 		}
@@ -1,16 +1,16 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
-	"gopkg.in/yaml.v3"
-
+	"github.com/chidinma21/env-lint/internal/envfile"
+	"github.com/chidinma21/env-lint/internal/report"
 	"github.com/chidinma21/env-lint/internal/validator"
 	"github.com/fatih/color"
-	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 )
 
@@ -26,6 +26,17 @@ var schemaFile string
 var suppressWarnings bool
 var strictMode bool
 var failFast bool
+var output string
+var profile string
+
+// reportWriters holds the machine-readable output formats, keyed by the
+// value of the --output flag.
+var reportWriters = map[string]func(io.Writer, report.Report) error{
+	"json":   report.WriteJSON,
+	"sarif":  report.WriteSARIF,
+	"junit":  report.WriteJUnit,
+	"github": report.WriteGitHub,
+}
 
 var validateCmd = &cobra.Command{
 	Use:   "validate",
@@ -34,74 +45,92 @@ var validateCmd = &cobra.Command{
 
 You can specify which keys are required and what type of value (string, number, boolean) each should have.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		writeReport, ok := reportWriters[output]
+		if !ok && output != "text" {
+			fmt.Fprintf(os.Stderr, "%s Unsupported output format: %s\n", fail("❌"), output)
+			os.Exit(1)
+		}
+		textOutput := output == "text"
+
 		// Load .env file
-		envMap, err := godotenv.Read(envFile)
+		env, err := envfile.Read(envFile)
 		if err != nil {
-			fmt.Printf("%s Failed to read .env file: %v\n", fail("❌"), err)
+			fmt.Fprintf(os.Stderr, "%s Failed to read .env file: %v\n", fail("❌"), err)
 			os.Exit(1)
 		}
-		fmt.Println(success("🚀 .env file loaded successfully"))
+		if textOutput {
+			fmt.Println(success("🚀 .env file loaded successfully"))
+		}
 
 		// Load schema
 		schemaData, err := os.ReadFile(schemaFile)
 		if err != nil {
-			fmt.Printf("%s Failed to read schema file: %v\n", fail("❌"), err)
+			fmt.Fprintf(os.Stderr, "%s Failed to read schema file: %v\n", fail("❌"), err)
 			os.Exit(1)
 		}
 
 		ext := filepath.Ext(schemaFile)
-		var schema map[string]validator.SchemaRule
+		var schemaFormat string
 		switch ext {
 		case ".json":
-			if err := json.Unmarshal(schemaData, &schema); err != nil {
-				fmt.Printf("%s Invalid JSON schema: %v\n", fail("❌"), err)
-				os.Exit(1)
-			}
+			schemaFormat = "json"
 		case ".yaml", ".yml":
-			if err := yaml.Unmarshal(schemaData, &schema); err != nil {
-				fmt.Printf("%s Invalid YAML schema: %v\n", fail("❌"), err)
-				os.Exit(1)
-			}
+			schemaFormat = "yaml"
 		default:
-			fmt.Printf("%s Unsupported schema format: %s\n", fail("❌"), ext)
+			fmt.Fprintf(os.Stderr, "%s Unsupported schema format: %s\n", fail("❌"), ext)
 			os.Exit(1)
 		}
 
-		fmt.Println(success("🚀 schema file loaded successfully"))
-
-		// Validate
-		fmt.Println(debug("\n🔍 Validating environment variables..."))
+		schema, err := validator.ParseSchema(schemaData, schemaFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s Invalid %s schema: %v\n", fail("❌"), strings.ToUpper(schemaFormat), err)
+			os.Exit(1)
+		}
 
-		validateRes := validator.ValidateEnv(envMap, schema, failFast, strictMode)
+		if profile != "" {
+			if schema, err = schema.WithProfile(profile); err != nil {
+				fmt.Fprintf(os.Stderr, "%s Invalid profile: %v\n", fail("❌"), err)
+				os.Exit(1)
+			}
+		}
 
-		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		if textOutput {
+			fmt.Println(success("🚀 schema file loaded successfully"))
+			if profile != "" {
+				fmt.Println(success("🚀 using profile " + profile))
+			}
+			fmt.Println(debug("\n🔍 Validating environment variables..."))
+		}
 
-		if !validateRes.Passed {
-			for key, value := range validateRes.Errors {
-				fmt.Printf("%-14s %-25s %s\n", fail("ERROR"), key, value)
+		// Validate
+		validateRes := validator.Validate(env.Values, schema, validator.Options{
+			FailFast:   failFast,
+			StrictMode: strictMode,
+			Positions:  env.Positions,
+			BaseDir:    filepath.Dir(envFile),
+		})
+
+		if textOutput {
+			printTextReport(validateRes)
+		} else {
+			if suppressWarnings {
+				validateRes = validateRes.WithoutWarnings()
 			}
-			if !suppressWarnings {
-				printValidationWarnings(validateRes.Warnings)
+			rep := report.Report{
+				EnvFile:    envFile,
+				SchemaFile: schemaFile,
+				Profile:    profile,
+				Schema:     schema,
+				Result:     validateRes,
 			}
-
-			if strictMode {
-				fmt.Println("\n")
-				fmt.Println(fail("❌ Strict Mode: Extra keys found in .env not in schema: "))
-				for _, k := range validateRes.ExtraKeys {
-					fmt.Printf("   - %s\n", k)
-				}
+			if err := writeReport(os.Stdout, rep); err != nil {
+				fmt.Fprintf(os.Stderr, "%s Failed to write report: %v\n", fail("❌"), err)
+				os.Exit(1)
 			}
+		}
 
-			fmt.Println("\n")
-			fmt.Println(fail("❌ Validation failed. Please fix the errors above."))
-
+		if !validateRes.Passed {
 			os.Exit(1)
-		} else {
-			fmt.Println(success("✅ All checks passed. Your .env config looks great!"))
-			if !suppressWarnings {
-				printValidationWarnings(validateRes.Warnings)
-			}
-			fmt.Println("\n")
 		}
 	},
 }
@@ -113,14 +142,47 @@ func init() {
 	validateCmd.Flags().BoolVarP(&suppressWarnings, "suppress-warnings", "w", false, "Suppress warning messages in output")
 	validateCmd.Flags().BoolVarP(&strictMode, "strict-mode", "t", false, "Fail if extra keys exist in .env that are not in schema")
 	validateCmd.Flags().BoolVarP(&failFast, "fail-fast", "f", false, "Stop validation after the first error")
+	validateCmd.Flags().StringVarP(&output, "output", "o", "text", "Output format: text, json, sarif, junit, github")
+	validateCmd.Flags().StringVarP(&profile, "profile", "p", "", "Schema profile to apply, e.g. production")
 }
 
-func printValidationWarnings(warnings map[string]string) {
-	if len(warnings) == 0 {
-		return
+func printTextReport(validateRes validator.ValidationResult) {
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	if !validateRes.Passed {
+		for _, f := range validateRes.Findings {
+			// Extra keys are listed in their own section below.
+			if f.Severity == validator.SeverityError && f.Rule != "strict" {
+				fmt.Printf("%-14s %-6s %-25s %s\n", fail("ERROR"), f.Code, f.Key, f.Message)
+			}
+		}
+		if !suppressWarnings {
+			printValidationWarnings(validateRes.Findings)
+		}
+
+		if strictMode {
+			fmt.Print("\n\n")
+			fmt.Println(fail("❌ Strict Mode: Extra keys found in .env not in schema: "))
+			for _, k := range validateRes.ExtraKeys {
+				fmt.Printf("   - %s\n", k)
+			}
+		}
+
+		fmt.Print("\n\n")
+		fmt.Println(fail("❌ Validation failed. Please fix the errors above."))
+	} else {
+		fmt.Println(success("✅ All checks passed. Your .env config looks great!"))
+		if !suppressWarnings {
+			printValidationWarnings(validateRes.Findings)
+		}
+		fmt.Print("\n\n")
 	}
+}
 
-	for key, value := range warnings {
-		fmt.Printf("%-14s %-25s %s\n", warn("WARN"), key, value)
+func printValidationWarnings(findings []validator.Finding) {
+	for _, f := range findings {
+		if f.Severity == validator.SeverityWarning {
+			fmt.Printf("%-14s %-6s %-25s %s\n", warn("WARN"), f.Code, f.Key, f.Message)
+		}
 	}
 }
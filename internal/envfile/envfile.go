@@ -0,0 +1,118 @@
+package envfile
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// Position is the place in a .env file where a key is defined. Line and
+// Column are 1-based; Column points at the first character of the key.
+type Position struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// File is a parsed .env file.
+type File struct {
+	Path      string
+	Values    map[string]string
+	Positions map[string]Position
+}
+
+// Read loads a .env file. Values are parsed by godotenv, so quoting, escapes
+// and variable expansion behave exactly as before; Read additionally records
+// the position of every key. When a key is defined more than once the last
+// definition wins, matching the value that is loaded.
+func Read(path string) (*File, error) {
+	values, err := godotenv.Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	positions, err := locateKeys(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{
+		Path:      path,
+		Values:    values,
+		Positions: positions,
+	}, nil
+}
+
+func locateKeys(path string) (map[string]Position, error) {
+	// The file is read as a whole because values such as inline
+	// certificates can be longer than a bufio.Scanner line.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]Position)
+	lineNo := 0
+	// openQuote is the quote character of a value that spans several lines,
+	// or 0 when the next line starts a new entry.
+	var openQuote byte
+
+	for _, line := range strings.Split(string(data), "\n") {
+		lineNo++
+		line = strings.TrimSuffix(line, "\r")
+
+		if openQuote != 0 {
+			if closesQuote(line, openQuote) {
+				openQuote = 0
+			}
+			continue
+		}
+
+		trimmed := strings.TrimLeft(line, " \t")
+		col := len(line) - len(trimmed) + 1
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(trimmed, "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			rest = strings.TrimLeft(rest, " \t")
+			col += len(trimmed) - len(rest)
+			trimmed = rest
+		}
+
+		end := strings.IndexAny(trimmed, "=:")
+		if end <= 0 {
+			continue
+		}
+		key := strings.TrimRight(trimmed[:end], " \t")
+		if key == "" || strings.ContainsAny(key, " \t") {
+			continue
+		}
+		positions[key] = Position{File: path, Line: lineNo, Column: col}
+
+		value := strings.TrimLeft(trimmed[end+1:], " \t")
+		if len(value) > 0 && (value[0] == '"' || value[0] == '\'') {
+			if !closesQuote(value[1:], value[0]) {
+				openQuote = value[0]
+			}
+		}
+	}
+
+	return positions, nil
+}
+
+// closesQuote reports whether s contains a closing quote. Like godotenv, a
+// quote preceded by a backslash does not close the value.
+func closesQuote(s string, quote byte) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == quote && (i == 0 || s[i-1] != '\\') {
+			return true
+		}
+	}
+	return false
+}
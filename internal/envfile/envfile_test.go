@@ -0,0 +1,75 @@
+package envfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	content := `# comment
+PORT=3000
+  export APP_NAME="env-lint"
+CERT="-----BEGIN-----
+abc
+-----END-----"
+DEBUG: true
+PORT=4000
+`
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+
+	wantValues := map[string]string{
+		"PORT":     "4000",
+		"APP_NAME": "env-lint",
+		"CERT":     "-----BEGIN-----\nabc\n-----END-----",
+		"DEBUG":    "true",
+	}
+	for k, v := range wantValues {
+		if got.Values[k] != v {
+			t.Errorf("Expected value of %s to be %q, got %q", k, v, got.Values[k])
+		}
+	}
+
+	wantPositions := map[string]Position{
+		"PORT":     {File: path, Line: 8, Column: 1},
+		"APP_NAME": {File: path, Line: 3, Column: 10},
+		"CERT":     {File: path, Line: 4, Column: 1},
+		"DEBUG":    {File: path, Line: 7, Column: 1},
+	}
+	if len(got.Positions) != len(wantPositions) {
+		t.Errorf("Expected %d positions, got %d: %v", len(wantPositions), len(got.Positions), got.Positions)
+	}
+	for k, v := range wantPositions {
+		if got.Positions[k] != v {
+			t.Errorf("Expected position of %s to be %v, got %v", k, v, got.Positions[k])
+		}
+	}
+}
+
+func TestReadLongLine(t *testing.T) {
+	long := strings.Repeat("A", 70*1024)
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("KEY="+long+"\r\nPORT=3000\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if got.Values["KEY"] != long {
+		t.Errorf("Expected the long value to be read in full, got %d bytes", len(got.Values["KEY"]))
+	}
+	if want := (Position{File: path, Line: 2, Column: 1}); got.Positions["PORT"] != want {
+		t.Errorf("Expected position of PORT to be %v, got %v", want, got.Positions["PORT"])
+	}
+}
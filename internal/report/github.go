@@ -0,0 +1,42 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// WriteGitHub writes the report as GitHub Actions workflow commands, so every
+// finding shows up as an annotation on the .env file in the pull request
+// diff.
+func WriteGitHub(w io.Writer, r Report) error {
+	for _, f := range r.Result.Findings {
+		props := []string{"file=" + escapeProperty(filepath.ToSlash(r.EnvFile))}
+		if f.Position != nil {
+			props = append(props, fmt.Sprintf("line=%d", f.Position.Line), fmt.Sprintf("col=%d", f.Position.Column))
+		}
+		props = append(props, "title="+escapeProperty(toolName+" "+f.Code+" ("+f.Key+")"))
+
+		_, err := fmt.Fprintf(w, "::%s %s::%s\n", f.Severity, strings.Join(props, ","), escapeData(f.Key+": "+f.Message))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeData escapes the message part of a workflow command.
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeProperty escapes a property value of a workflow command, which
+// additionally must not contain the ':' and ',' separators.
+func escapeProperty(s string) string {
+	s = escapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
@@ -0,0 +1,135 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/chidinma21/env-lint/internal/validator"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML. Every schema key becomes a test
+// case, in schema order, that fails when the key has errors; warnings are attached as the test
+// case's output. Every key group and constraint is a test case with the
+// class name "groups" or "constraints". In strict mode every extra key is an
+// additional failing test case.
+func WriteJUnit(w io.Writer, r Report) error {
+	suite := junitTestSuite{Name: r.EnvFile}
+
+	for _, key := range r.Schema.Keys() {
+		tc := junitTestCase{
+			Name:      key,
+			ClassName: r.SchemaFile,
+			Failure:   junitFailureOf(r.Result.FindingsFor(key, validator.SeverityError)),
+			SystemOut: joinMessages(r.Result.FindingsFor(key, validator.SeverityWarning)),
+		}
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	for _, g := range r.Schema.Groups {
+		tc := junitTestCase{
+			Name:      g.DisplayName(),
+			ClassName: "groups",
+			Failure:   junitFailureOf(r.Result.FindingsFor(g.DisplayName(), validator.SeverityError)),
+		}
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	for _, c := range r.Schema.Constraints {
+		tc := junitTestCase{
+			Name:      c.DisplayName(),
+			ClassName: "constraints",
+			Failure:   junitFailureOf(r.Result.FindingsFor(c.DisplayName(), validator.SeverityError)),
+			SystemOut: joinMessages(r.Result.FindingsFor(c.DisplayName(), validator.SeverityWarning)),
+		}
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	for _, key := range r.Result.ExtraKeys {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      key,
+			ClassName: "strict-mode",
+			Failure:   junitFailureOf(r.Result.FindingsFor(key, validator.SeverityError)),
+		})
+		suite.Failures++
+	}
+	suite.Tests = len(suite.TestCases)
+
+	doc := junitTestSuites{
+		Name:     toolName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitFailureOf turns the errors of a key into a single failure whose
+// message is the first error and whose body lists all of them.
+func junitFailureOf(errs []validator.Finding) *junitFailure {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &junitFailure{
+		Message: errs[0].Message,
+		Type:    errs[0].Code,
+		Text:    joinMessages(errs),
+	}
+}
+
+func joinMessages(findings []validator.Finding) string {
+	msgs := make([]string, len(findings))
+	for i, f := range findings {
+		msgs[i] = f.Message
+	}
+	return strings.Join(msgs, "\n")
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/chidinma21/env-lint/internal/envfile"
+	"github.com/chidinma21/env-lint/internal/validator"
+)
+
+// Version is the version of the JSON report format. It is bumped whenever a
+// field is removed or changes meaning; new fields may be added without a bump.
+const Version = 1
+
+// Report bundles a validation result with the inputs that produced it, so
+// every output format can describe where the findings came from.
+type Report struct {
+	EnvFile    string
+	SchemaFile string
+	// Profile is the schema profile the rules were taken from, if any.
+	Profile string
+	Schema  validator.Schema
+	Result  validator.ValidationResult
+}
+
+type jsonReport struct {
+	Version    int                 `json:"version"`
+	EnvFile    string              `json:"envFile"`
+	SchemaFile string              `json:"schemaFile"`
+	Profile    string              `json:"profile,omitempty"`
+	Passed     bool                `json:"passed"`
+	Findings   []validator.Finding `json:"findings"`
+	Errors     map[string]string   `json:"errors"`
+	Warnings   map[string]string   `json:"warnings"`
+	ExtraKeys  []string            `json:"extraKeys"`
+
+	Positions map[string]envfile.Position `json:"positions"`
+}
+
+// WriteJSON writes the report as a single indented JSON document.
+func WriteJSON(w io.Writer, r Report) error {
+	doc := jsonReport{
+		Version:    Version,
+		EnvFile:    r.EnvFile,
+		SchemaFile: r.SchemaFile,
+		Profile:    r.Profile,
+		Passed:     r.Result.Passed,
+		Findings:   r.Result.Findings,
+		Errors:     r.Result.Errors,
+		Warnings:   r.Result.Warnings,
+		ExtraKeys:  r.Result.ExtraKeys,
+		Positions:  r.Result.Positions,
+	}
+
+	// Keep empty collections as {} and [] so consumers never see null.
+	if doc.Findings == nil {
+		doc.Findings = []validator.Finding{}
+	}
+	if doc.Errors == nil {
+		doc.Errors = map[string]string{}
+	}
+	if doc.Warnings == nil {
+		doc.Warnings = map[string]string{}
+	}
+	if doc.ExtraKeys == nil {
+		doc.ExtraKeys = []string{}
+	}
+	if doc.Positions == nil {
+		doc.Positions = map[string]envfile.Position{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(doc)
+}
@@ -0,0 +1,274 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/chidinma21/env-lint/internal/envfile"
+	"github.com/chidinma21/env-lint/internal/validator"
+)
+
+// testReport returns a failing report with two errors on PORT, a missing
+// required key, a warning and an extra key. Like Validate, it keeps the extra
+// key out of Errors.
+func testReport() Report {
+	portPos := envfile.Position{File: "config/.env", Line: 2, Column: 8}
+	extraPos := envfile.Position{File: "config/.env", Line: 5, Column: 1}
+
+	return Report{
+		EnvFile:    "config/.env",
+		SchemaFile: "schema.json",
+		Schema: validator.Schema{
+			Rules: map[string]validator.SchemaRule{
+				"PORT":    {Type: "number"},
+				"API_KEY": {Type: "string", Required: true},
+				"DEBUG":   {Type: "boolean"},
+			},
+			Order: []string{"PORT", "API_KEY", "DEBUG"},
+		},
+		Result: validator.ValidationResult{
+			Passed: false,
+			Findings: []validator.Finding{
+				{Key: "PORT", Code: "EL002", Rule: "allowed", Severity: validator.SeverityError, Message: "Value '80' is not allowed. Expected one of: [3000]", Position: &portPos},
+				{Key: "PORT", Code: "EL007", Rule: "min", Severity: validator.SeverityError, Message: "Expected number >= 1000.00 but got: 80.00", Position: &portPos},
+				{Key: "API_KEY", Code: "EL001", Rule: "required", Severity: validator.SeverityError, Message: "Missing required key"},
+				{Key: "DEBUG", Code: "EL014", Rule: "required", Severity: validator.SeverityWarning, Message: "Missing optional key (ok)"},
+				{Key: "EXTRA", Code: "EL012", Rule: "strict", Severity: validator.SeverityError, Message: "Key is not defined in schema", Position: &extraPos},
+			},
+			Errors: map[string]string{
+				"PORT":    "Value '80' is not allowed. Expected one of: [3000]",
+				"API_KEY": "Missing required key",
+			},
+			Warnings: map[string]string{
+				"DEBUG": "Missing optional key (ok)",
+			},
+			ExtraKeys: []string{"EXTRA"},
+			Positions: map[string]envfile.Position{
+				"PORT":  portPos,
+				"EXTRA": extraPos,
+			},
+		},
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, testReport()); err != nil {
+		t.Fatalf("WriteJSON returned error: %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+
+	if got["version"] != float64(Version) {
+		t.Errorf("Expected version %d, got %v", Version, got["version"])
+	}
+	if got["passed"] != false {
+		t.Errorf("Expected passed = false, got %v", got["passed"])
+	}
+	if _, ok := got["profile"]; ok {
+		t.Errorf("Expected no profile in report without one, got %v", got["profile"])
+	}
+	if got["envFile"] != "config/.env" || got["schemaFile"] != "schema.json" {
+		t.Errorf("Unexpected files in report: %v, %v", got["envFile"], got["schemaFile"])
+	}
+	if errs, ok := got["errors"].(map[string]interface{}); !ok || errs["API_KEY"] != "Missing required key" {
+		t.Errorf("Unexpected errors in report: %v", got["errors"])
+	}
+
+	findings, ok := got["findings"].([]interface{})
+	if !ok || len(findings) != 5 {
+		t.Fatalf("Expected 5 findings, got %v", got["findings"])
+	}
+	first := findings[0].(map[string]interface{})
+	if first["key"] != "PORT" || first["code"] != "EL002" || first["rule"] != "allowed" || first["severity"] != "error" {
+		t.Errorf("Unexpected first finding: %v", first)
+	}
+	if pos, ok := first["position"].(map[string]interface{}); !ok || pos["line"] != float64(2) || pos["column"] != float64(8) {
+		t.Errorf("Unexpected position of first finding: %v", first["position"])
+	}
+}
+
+func TestWriteJSONProfile(t *testing.T) {
+	r := testReport()
+	r.Profile = "production"
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, r); err != nil {
+		t.Fatalf("WriteJSON returned error: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	if got["profile"] != "production" {
+		t.Errorf("Expected profile production, got %v", got["profile"])
+	}
+}
+
+func TestWriteJSONEmptyCollections(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, Report{Result: validator.ValidationResult{Passed: true}}); err != nil {
+		t.Fatalf("WriteJSON returned error: %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	for _, field := range []string{"findings", "extraKeys"} {
+		if list, ok := got[field].([]interface{}); !ok || len(list) != 0 {
+			t.Errorf("Expected empty %s array, got %v", field, got[field])
+		}
+	}
+	for _, field := range []string{"errors", "warnings", "positions"} {
+		if obj, ok := got[field].(map[string]interface{}); !ok || len(obj) != 0 {
+			t.Errorf("Expected empty %s object, got %v", field, got[field])
+		}
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, testReport()); err != nil {
+		t.Fatalf("WriteSARIF returned error: %v", err)
+	}
+
+	var got sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("SARIF log is not valid JSON: %v", err)
+	}
+	if got.Version != "2.1.0" || len(got.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF envelope: version %q, %d runs", got.Version, len(got.Runs))
+	}
+
+	rules := got.Runs[0].Tool.Driver.Rules
+	if len(rules) != 5 {
+		t.Fatalf("Expected 5 distinct rules, got %d", len(rules))
+	}
+	if rules[0].ID != "EL002" || rules[0].Name != "allowed" || rules[0].ShortDescription.Text == "" {
+		t.Errorf("Unexpected first rule: %+v", rules[0])
+	}
+
+	results := got.Runs[0].Results
+	want := []struct {
+		ruleID string
+		level  string
+		line   int
+		column int
+	}{
+		{"EL002", "error", 2, 8},
+		{"EL007", "error", 2, 8},
+		{"EL001", "error", 1, 0},
+		{"EL014", "warning", 1, 0},
+		{"EL012", "error", 5, 1},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %d", len(want), len(results))
+	}
+	for i, w := range want {
+		res := results[i]
+		if res.RuleID != w.ruleID || res.Level != w.level {
+			t.Errorf("Result %d: expected %s/%s, got %s/%s", i, w.ruleID, w.level, res.RuleID, res.Level)
+		}
+		loc := res.Locations[0].PhysicalLocation
+		if loc.ArtifactLocation.URI != "config/.env" || loc.Region.StartLine != w.line || loc.Region.StartColumn != w.column {
+			t.Errorf("Result %d: expected config/.env:%d:%d, got %s:%d:%d", i, w.line, w.column,
+				loc.ArtifactLocation.URI, loc.Region.StartLine, loc.Region.StartColumn)
+		}
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, testReport()); err != nil {
+		t.Fatalf("WriteJUnit returned error: %v", err)
+	}
+
+	var got junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("JUnit report is not valid XML: %v", err)
+	}
+	if got.Tests != 4 || got.Failures != 3 {
+		t.Errorf("Expected 4 tests and 3 failures, got %d and %d", got.Tests, got.Failures)
+	}
+
+	cases := got.Suites[0].TestCases
+	if len(cases) != 4 {
+		t.Fatalf("Expected 4 test cases, got %d", len(cases))
+	}
+	wantText := "Value '80' is not allowed. Expected one of: [3000]\nExpected number >= 1000.00 but got: 80.00"
+	if cases[0].Name != "PORT" || cases[0].Failure == nil || cases[0].Failure.Text != wantText {
+		t.Errorf("Unexpected PORT test case: %+v", cases[0])
+	}
+	if cases[1].Name != "API_KEY" || cases[1].Failure == nil || cases[1].Failure.Message != "Missing required key" || cases[1].Failure.Type != "EL001" {
+		t.Errorf("Unexpected API_KEY test case: %+v", cases[1])
+	}
+	if cases[2].Name != "DEBUG" || cases[2].Failure != nil || cases[2].SystemOut != "Missing optional key (ok)" {
+		t.Errorf("Unexpected DEBUG test case: %+v", cases[2])
+	}
+	if cases[3].Name != "EXTRA" || cases[3].ClassName != "strict-mode" || cases[3].Failure == nil {
+		t.Errorf("Unexpected EXTRA test case: %+v", cases[3])
+	}
+}
+
+func TestWriteJUnitGroups(t *testing.T) {
+	schema := validator.Schema{
+		Rules: map[string]validator.SchemaRule{"DATABASE_URL": {Type: "url"}},
+		Order: []string{"DATABASE_URL"},
+		Groups: []validator.Group{
+			{Type: "oneOf", Keys: []validator.GroupMember{{"DATABASE_URL"}, {"DB_HOST", "DB_NAME"}}, Name: "database"},
+			{Type: "allOrNone", Keys: []validator.GroupMember{{"AWS_ACCESS_KEY_ID"}, {"AWS_SECRET_ACCESS_KEY"}}},
+		},
+	}
+	env := map[string]string{"DATABASE_URL": "postgres://db/app", "DB_HOST": "db", "DB_NAME": "app"}
+	r := Report{EnvFile: ".env", SchemaFile: "schema.json", Schema: schema, Result: validator.Validate(env, schema, validator.Options{})}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, r); err != nil {
+		t.Fatalf("WriteJUnit returned error: %v", err)
+	}
+	var got junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("JUnit report is not valid XML: %v", err)
+	}
+
+	cases := got.Suites[0].TestCases
+	if len(cases) != 3 || got.Failures != 1 {
+		t.Fatalf("Expected 3 test cases and 1 failure, got %d and %d", len(cases), got.Failures)
+	}
+	if cases[1].Name != "database" || cases[1].ClassName != "groups" || cases[1].Failure == nil || cases[1].Failure.Type != validator.CodeGroupOneOf {
+		t.Errorf("Unexpected database group test case: %+v", cases[1])
+	}
+	if cases[2].Name != "allOrNone(AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY)" || cases[2].Failure != nil {
+		t.Errorf("Unexpected AWS group test case: %+v", cases[2])
+	}
+}
+
+func TestWriteGitHub(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteGitHub(&buf, testReport()); err != nil {
+		t.Fatalf("WriteGitHub returned error: %v", err)
+	}
+
+	want := "::error file=config/.env,line=2,col=8,title=env-lint EL002 (PORT)::PORT: Value '80' is not allowed. Expected one of: [3000]\n" +
+		"::error file=config/.env,line=2,col=8,title=env-lint EL007 (PORT)::PORT: Expected number >= 1000.00 but got: 80.00\n" +
+		"::error file=config/.env,title=env-lint EL001 (API_KEY)::API_KEY: Missing required key\n" +
+		"::warning file=config/.env,title=env-lint EL014 (DEBUG)::DEBUG: Missing optional key (ok)\n" +
+		"::error file=config/.env,line=5,col=1,title=env-lint EL012 (EXTRA)::EXTRA: Key is not defined in schema\n"
+	if buf.String() != want {
+		t.Errorf("Unexpected annotations:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestEscapeProperty(t *testing.T) {
+	got := escapeProperty("a:b,c%d\ne")
+	want := "a%3Ab%2Cc%25d%0Ae"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
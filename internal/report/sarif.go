@@ -0,0 +1,136 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+
+	"github.com/chidinma21/env-lint/internal/validator"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "env-lint"
+	toolURI      = "https://github.com/chidinma21/env-lint"
+	codesURI     = toolURI + "#-error-codes"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	HelpURI          string       `json:"helpUri"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF writes the report as a SARIF 2.1.0 log with one result per
+// finding. The rule ID of a result is the code of the check that produced
+// it.
+func WriteSARIF(w io.Writer, r Report) error {
+	results := []sarifResult{}
+	rules := []sarifRule{}
+	seen := make(map[string]bool)
+
+	for _, f := range r.Result.Findings {
+		if !seen[f.Code] {
+			seen[f.Code] = true
+			check, _ := validator.LookupCheck(f.Code)
+			rules = append(rules, sarifRule{
+				ID:               f.Code,
+				Name:             f.Rule,
+				ShortDescription: sarifMessage{Text: check.Description},
+				HelpURI:          codesURI,
+			})
+		}
+		results = append(results, r.sarifResult(f))
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           toolName,
+				InformationURI: toolURI,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(log)
+}
+
+func (r Report) sarifResult(f validator.Finding) sarifResult {
+	// Keys that are not in the .env file (e.g. missing required keys) are
+	// reported against the top of the file.
+	region := sarifRegion{StartLine: 1}
+	if f.Position != nil {
+		region = sarifRegion{StartLine: f.Position.Line, StartColumn: f.Position.Column}
+	}
+
+	return sarifResult{
+		RuleID:  f.Code,
+		Level:   string(f.Severity),
+		Message: sarifMessage{Text: f.Key + ": " + f.Message},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(r.EnvFile)},
+				Region:           region,
+			},
+		}},
+	}
+}
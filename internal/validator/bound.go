@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Bound is the value of a min or max rule. It is written as a plain number,
+// or as a string with a unit for types that have one ("30s", "64MiB"). The
+// zero value means the bound is not set, as does null, which lets a profile
+// remove a bound.
+type Bound string
+
+func (b *Bound) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case nil:
+		*b = ""
+	case float64:
+		*b = Bound(string(data))
+	case string:
+		*b = Bound(v)
+	default:
+		return fmt.Errorf("bound must be a number or a string, got %s", data)
+	}
+	return nil
+}
+
+func (b Bound) MarshalJSON() ([]byte, error) {
+	if _, err := strconv.ParseFloat(string(b), 64); err == nil {
+		return []byte(b), nil
+	}
+	return json.Marshal(string(b))
+}
+
+func (b *Bound) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("bound must be a number or a string")
+	}
+	if node.Tag == "!!null" {
+		*b = ""
+		return nil
+	}
+	*b = Bound(node.Value)
+	return nil
+}
+
+func (b Bound) MarshalYAML() (interface{}, error) {
+	if f, err := strconv.ParseFloat(string(b), 64); err == nil {
+		return f, nil
+	}
+	return string(b), nil
+}
+
+// checkRange reports a value outside rule.Min and rule.Max. num is the value
+// converted by parse, which also converts the bounds; format renders numbers
+// in messages. Bounds that parse cannot read are reported as warnings.
+func checkRange(typeName, value string, num float64, rule SchemaRule, add addFunc,
+	parse func(string) (float64, error), format func(float64) string) {
+	if rule.Min != "" {
+		if min, err := parse(string(rule.Min)); err != nil {
+			add(CodeInvalidBound, fmt.Sprintf("Invalid min '%s' for type %s", rule.Min, typeName), "", string(rule.Min))
+		} else if num < min {
+			add(CodeMin, fmt.Sprintf("Expected %s >= %s but got: %s", typeName, format(min), format(num)), ">= "+string(rule.Min), value)
+		}
+	}
+	if rule.Max != "" {
+		if max, err := parse(string(rule.Max)); err != nil {
+			add(CodeInvalidBound, fmt.Sprintf("Invalid max '%s' for type %s", rule.Max, typeName), "", string(rule.Max))
+		} else if num > max {
+			add(CodeMax, fmt.Sprintf("Expected %s <= %s but got: %s", typeName, format(max), format(num)), "<= "+string(rule.Max), value)
+		}
+	}
+}
+
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
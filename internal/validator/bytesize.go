@@ -0,0 +1,73 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// byteUnits maps the lower-cased unit suffixes of the bytesize type to their
+// size in bytes. SI units (KB, MB, …) are powers of 1000, IEC units (KiB,
+// MiB, …) powers of 1024. The single-letter forms follow SI.
+var byteUnits = map[string]float64{
+	"": 1, "b": 1,
+	"k": 1e3, "kb": 1e3, "kib": 1 << 10, "ki": 1 << 10,
+	"m": 1e6, "mb": 1e6, "mib": 1 << 20, "mi": 1 << 20,
+	"g": 1e9, "gb": 1e9, "gib": 1 << 30, "gi": 1 << 30,
+	"t": 1e12, "tb": 1e12, "tib": 1 << 40, "ti": 1 << 40,
+	"p": 1e15, "pb": 1e15, "pib": 1 << 50, "pi": 1 << 50,
+	"e": 1e18, "eb": 1e18, "eib": 1 << 60, "ei": 1 << 60,
+}
+
+// parseByteSize parses a size such as "512MB", "2GiB", "1.5 G" or "1024"
+// into a number of bytes.
+func parseByteSize(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i == -1 {
+		i = len(s)
+	}
+
+	num, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	unit, ok := byteUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit in size %q", s)
+	}
+	return num * unit, nil
+}
+
+// formatByteSize renders a number of bytes with the largest IEC or SI unit
+// that represents it exactly.
+func formatByteSize(f float64) string {
+	units := []struct {
+		name string
+		size float64
+	}{
+		{"EiB", 1 << 60}, {"EB", 1e18}, {"PiB", 1 << 50}, {"PB", 1e15},
+		{"TiB", 1 << 40}, {"TB", 1e12}, {"GiB", 1 << 30}, {"GB", 1e9},
+		{"MiB", 1 << 20}, {"MB", 1e6}, {"KiB", 1 << 10}, {"KB", 1e3},
+	}
+	for _, u := range units {
+		if f >= u.size && f/u.size == float64(int64(f/u.size)) {
+			return formatFloat(f/u.size) + u.name
+		}
+	}
+	return formatFloat(f) + "B"
+}
+
+// checkByteSize validates a value of type bytesize: a non-negative number
+// with an optional SI or IEC unit. min and max may be written with units.
+func checkByteSize(value string, rule SchemaRule, add addFunc) {
+	size, err := parseByteSize(value)
+	if err != nil {
+		add(CodeInvalidByteSize, fmt.Sprintf("Expected byte size (e.g. 512MB, 2GiB) but got: %s", value), "bytesize", value)
+		return
+	}
+
+	checkRange("bytesize", value, size, rule, add, parseByteSize, formatByteSize)
+}
@@ -0,0 +1,174 @@
+package validator
+
+// Codes identify each kind of finding. A code never changes meaning once it
+// has been released, so it can be used in suppressions, documentation links
+// and dashboards instead of the English message.
+const (
+	CodeMissingRequired   = "EL001"
+	CodeNotAllowed        = "EL002"
+	CodePatternMismatch   = "EL003"
+	CodeLength            = "EL004"
+	CodeMinLength         = "EL005"
+	CodeMaxLength         = "EL006"
+	CodeMin               = "EL007"
+	CodeMax               = "EL008"
+	CodeNotNumber         = "EL009"
+	CodeNotBoolean        = "EL010"
+	CodeUnknownType       = "EL011"
+	CodeExtraKey          = "EL012"
+	CodeInvalidPattern    = "EL013"
+	CodeMissingOptional   = "EL014"
+	CodeDefaultUsed       = "EL015"
+	CodeInvalidURL        = "EL016"
+	CodeURLScheme         = "EL017"
+	CodeURLHost           = "EL018"
+	CodeURLCredentials    = "EL019"
+	CodeURLPath           = "EL020"
+	CodeNotInteger        = "EL021"
+	CodeIntegerRange      = "EL022"
+	CodeUnknownFormat     = "EL023"
+	CodeInvalidPort       = "EL024"
+	CodePrivilegedPort    = "EL025"
+	CodeInvalidBound      = "EL026"
+	CodeInvalidDuration   = "EL027"
+	CodeInvalidByteSize   = "EL028"
+	CodeInvalidEmail      = "EL029"
+	CodeInvalidHostname   = "EL030"
+	CodeInvalidIP         = "EL031"
+	CodeInvalidCIDR       = "EL032"
+	CodeIPVersion         = "EL033"
+	CodeInvalidBase64     = "EL034"
+	CodeInvalidHex        = "EL035"
+	CodeInvalidUUID       = "EL036"
+	CodeUUIDVersion       = "EL037"
+	CodeInvalidJWT        = "EL038"
+	CodeMinItems          = "EL039"
+	CodeMaxItems          = "EL040"
+	CodeDuplicateItem     = "EL041"
+	CodeInvalidJSON       = "EL042"
+	CodeJSONSchema        = "EL043"
+	CodeInvalidDate       = "EL044"
+	CodeInvalidDateTime   = "EL045"
+	CodeInvalidTimezone   = "EL046"
+	CodePathNotAbsolute   = "EL047"
+	CodePathNotFound      = "EL048"
+	CodePathKind          = "EL049"
+	CodePathNotReadable   = "EL050"
+	CodePathNotWritable   = "EL051"
+	CodeInvalidSemver     = "EL052"
+	CodeSemverConstraint  = "EL053"
+	CodeInvalidConstraint = "EL054"
+	CodeInvalidCron       = "EL055"
+	CodeCronInterval      = "EL056"
+	CodeGroupOneOf        = "EL057"
+	CodeGroupAnyOf        = "EL058"
+	CodeGroupExclusive    = "EL059"
+	CodeGroupAllOrNone    = "EL060"
+	CodeConstraintFailed  = "EL061"
+	CodeConstraintError   = "EL062"
+	CodeDisallowed        = "EL063"
+)
+
+// Check describes one kind of finding: its code, the schema rule that
+// produces it and how serious it is.
+type Check struct {
+	Code        string
+	Rule        string
+	Severity    Severity
+	Description string
+}
+
+// Checks lists every check in code order.
+var Checks = []Check{
+	{CodeMissingRequired, "required", SeverityError, "A required key is missing from the .env file"},
+	{CodeNotAllowed, "allowed", SeverityError, "A value is not one of the allowed values"},
+	{CodePatternMismatch, "pattern", SeverityError, "A value does not match its pattern"},
+	{CodeLength, "length", SeverityError, "A value does not have the exact expected length"},
+	{CodeMinLength, "minLength", SeverityError, "A value is shorter than its minimum length"},
+	{CodeMaxLength, "maxLength", SeverityError, "A value is longer than its maximum length"},
+	{CodeMin, "min", SeverityError, "A value is below its minimum"},
+	{CodeMax, "max", SeverityError, "A value is above its maximum"},
+	{CodeNotNumber, "type", SeverityError, "A value of type number is not a number"},
+	{CodeNotBoolean, "type", SeverityError, "A value of type boolean is not true or false"},
+	{CodeUnknownType, "type", SeverityWarning, "The schema uses a type env-lint does not know; the value is not checked"},
+	{CodeExtraKey, "strict", SeverityError, "A key in the .env file is not defined in the schema (strict mode)"},
+	{CodeInvalidPattern, "pattern", SeverityWarning, "The schema pattern is not a valid regular expression"},
+	{CodeMissingOptional, "required", SeverityWarning, "An optional key without a default is missing"},
+	{CodeDefaultUsed, "default", SeverityWarning, "An optional key is missing and its default value is used"},
+	{CodeInvalidURL, "type", SeverityError, "A value of type url is not an absolute URL"},
+	{CodeURLScheme, "schemes", SeverityError, "A URL uses a scheme that is not allowed"},
+	{CodeURLHost, "requireHost", SeverityError, "A URL has no host"},
+	{CodeURLCredentials, "forbidCredentials", SeverityError, "A URL contains a username or password"},
+	{CodeURLPath, "requirePath", SeverityError, "A URL has no path"},
+	{CodeNotInteger, "type", SeverityError, "A value of type integer is not a whole number"},
+	{CodeIntegerRange, "format", SeverityError, "An integer does not fit its format (e.g. int32 or uint16)"},
+	{CodeUnknownFormat, "format", SeverityWarning, "The schema uses a format, version or kind env-lint does not know for the type; the value is not checked"},
+	{CodeInvalidPort, "type", SeverityError, "A value of type port is not an integer from 1 to 65535"},
+	{CodePrivilegedPort, "forbidPrivileged", SeverityError, "A port is privileged (below 1024)"},
+	{CodeInvalidBound, "min", SeverityWarning, "The schema min, max or minInterval cannot be read for the type; the bound is not checked"},
+	{CodeInvalidDuration, "type", SeverityError, "A value of type duration is not a Go duration (e.g. 30s, 5m, 1h30m)"},
+	{CodeInvalidByteSize, "type", SeverityError, "A value of type bytesize is not a size with an SI or IEC unit (e.g. 512MB, 2GiB)"},
+	{CodeInvalidEmail, "type", SeverityError, "A value of type email is not a bare email address"},
+	{CodeInvalidHostname, "type", SeverityError, "A value of type hostname is not a valid RFC 1123 hostname"},
+	{CodeInvalidIP, "type", SeverityError, "A value of type ip is not an IPv4 or IPv6 address"},
+	{CodeInvalidCIDR, "type", SeverityError, "A value of type cidr is not an IP prefix (e.g. 10.0.0.0/8)"},
+	{CodeIPVersion, "version", SeverityError, "An IP address or CIDR block is not of the required IP version"},
+	{CodeInvalidBase64, "type", SeverityError, "A value of type base64 is not base64-encoded"},
+	{CodeInvalidHex, "type", SeverityError, "A value of type hex is not an even number of hexadecimal digits"},
+	{CodeInvalidUUID, "type", SeverityError, "A value of type uuid is not a UUID in 8-4-4-4-12 form"},
+	{CodeUUIDVersion, "version", SeverityError, "A UUID is not of the required version"},
+	{CodeInvalidJWT, "type", SeverityError, "A value of type jwt is not three base64url segments with a JSON header and payload"},
+	{CodeMinItems, "minItems", SeverityError, "A list has fewer items than its minimum"},
+	{CodeMaxItems, "maxItems", SeverityError, "A list has more items than its maximum"},
+	{CodeDuplicateItem, "unique", SeverityError, "A list that must be unique contains the same item twice"},
+	{CodeInvalidJSON, "type", SeverityError, "A value of type json is not valid JSON"},
+	{CodeJSONSchema, "schema", SeverityError, "A JSON value does not match the schema of its rule"},
+	{CodeInvalidDate, "type", SeverityError, "A value of type date does not match its layout (default 2006-01-02)"},
+	{CodeInvalidDateTime, "type", SeverityError, "A value of type datetime does not match its layout (default RFC3339)"},
+	{CodeInvalidTimezone, "type", SeverityError, "A value of type timezone is not an IANA time zone name"},
+	{CodePathNotAbsolute, "absolute", SeverityError, "A path is not absolute"},
+	{CodePathNotFound, "mustExist", SeverityError, "A path does not exist"},
+	{CodePathKind, "kind", SeverityError, "A path is not of the required kind (file or dir)"},
+	{CodePathNotReadable, "readable", SeverityError, "A path cannot be read"},
+	{CodePathNotWritable, "writable", SeverityError, "A path cannot be written or created"},
+	{CodeInvalidSemver, "type", SeverityError, "A value of type semver is not a semantic version"},
+	{CodeSemverConstraint, "constraint", SeverityError, "A version does not satisfy its constraint"},
+	{CodeInvalidConstraint, "constraint", SeverityWarning, "The schema version constraint cannot be parsed; the constraint is not checked"},
+	{CodeInvalidCron, "type", SeverityError, "A value of type cron is not a valid cron expression or never runs"},
+	{CodeCronInterval, "minInterval", SeverityError, "A cron expression runs more often than its minimum interval"},
+	{CodeGroupOneOf, "oneOf", SeverityError, "Not exactly one member of a oneOf key group is set"},
+	{CodeGroupAnyOf, "anyOf", SeverityError, "No member of an anyOf key group is set"},
+	{CodeGroupExclusive, "exclusive", SeverityError, "More than one member of an exclusive key group is set"},
+	{CodeGroupAllOrNone, "allOrNone", SeverityError, "Only some keys of an allOrNone key group are set"},
+	{CodeConstraintFailed, "constraints", SeverityError, "A cross-key constraint does not hold"},
+	{CodeConstraintError, "constraints", SeverityWarning, "A cross-key constraint cannot be evaluated (e.g. it compares a duration with a string or an integer); it is not checked"},
+	{CodeDisallowed, "disallowed", SeverityError, "A value is one of the disallowed values"},
+}
+
+var checksByCode = func() map[string]Check {
+	m := make(map[string]Check, len(Checks))
+	for _, c := range Checks {
+		m[c.Code] = c
+	}
+	return m
+}()
+
+// LookupCheck returns the check with the given code.
+func LookupCheck(code string) (Check, bool) {
+	c, ok := checksByCode[code]
+	return c, ok
+}
+
+// newFinding returns a finding for code, taking its rule and severity from
+// the check table. The caller fills in the key.
+func newFinding(code, msg, expected, actual string) Finding {
+	c := checksByCode[code]
+	return Finding{
+		Code:     code,
+		Rule:     c.Rule,
+		Severity: c.Severity,
+		Message:  msg,
+		Expected: expected,
+		Actual:   actual,
+	}
+}
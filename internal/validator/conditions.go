@@ -0,0 +1,98 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Condition is the value of a requiredIf or requiredUnless rule: it maps
+// keys to the value they must have, or to a list of values one of which they
+// must have. A condition holds when all of its keys match.
+type Condition map[string]interface{}
+
+// holds reports whether every key of c has one of its values in env. Values
+// are compared with the type of the key's rule. A missing key never matches.
+func (c Condition) holds(env map[string]string, rules map[string]SchemaRule) bool {
+	for key, want := range c {
+		value, ok := env[key]
+		if !ok || !matchesAny(value, want, rules[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAny(value string, want interface{}, rule SchemaRule) bool {
+	if list, ok := want.([]interface{}); ok {
+		for _, w := range list {
+			if equalAs(rule, value, fmt.Sprintf("%v", w)) {
+				return true
+			}
+		}
+		return false
+	}
+	return equalAs(rule, value, fmt.Sprintf("%v", want))
+}
+
+// equalAs reports whether a and b are the same value of the rule's type, so
+// True matches true for a boolean key and 1m matches 60s for a duration.
+// Values that cannot be read with the type are compared as written.
+func equalAs(rule SchemaRule, a, b string) bool {
+	va, errA := typedValue(rule, a)
+	vb, errB := typedValue(rule, b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	switch va.kind {
+	case kindNumber:
+		return va.num == vb.num
+	case kindBool:
+		return va.b == vb.b
+	case kindVersion:
+		return va.ver.compare(vb.ver) == 0
+	}
+	return va.str == vb.str
+}
+
+// keys returns the keys of c in alphabetical order.
+func (c Condition) keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// String describes the condition for messages, e.g.
+// "MAIL_DRIVER=smtp and TLS_ENABLED is one of [true 1]".
+func (c Condition) String() string {
+	keys := c.keys()
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		if list, ok := c[key].([]interface{}); ok {
+			parts[i] = fmt.Sprintf("%s is one of %v", key, list)
+		} else {
+			parts[i] = fmt.Sprintf("%s=%v", key, c[key])
+		}
+	}
+	return strings.Join(parts, " and ")
+}
+
+// conditionallyRequired returns the finding for a missing key that rule
+// requires because of its requiredIf or requiredUnless condition. env holds
+// every known value, including defaults.
+func conditionallyRequired(rule SchemaRule, env map[string]string, rules map[string]SchemaRule) (Finding, bool) {
+	if len(rule.RequiredIf) > 0 && rule.RequiredIf.holds(env, rules) {
+		f := newFinding(CodeMissingRequired, "Missing key required when "+rule.RequiredIf.String(), rule.RequiredIf.String(), "")
+		f.Rule = "requiredIf"
+		return f, true
+	}
+	if len(rule.RequiredUnless) > 0 && !rule.RequiredUnless.holds(env, rules) {
+		f := newFinding(CodeMissingRequired, "Missing key required unless "+rule.RequiredUnless.String(), "not "+rule.RequiredUnless.String(), "")
+		f.Rule = "requiredUnless"
+		return f, true
+	}
+	return Finding{}, false
+}
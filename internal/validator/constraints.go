@@ -0,0 +1,78 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConstraintsKey is the reserved schema key that holds the cross-key
+// constraints.
+const ConstraintsKey = "$constraints"
+
+// Constraint is a boolean expression over the typed values of several keys,
+// e.g. "POOL_MIN <= POOL_MAX" or `len(ADMIN_EMAILS) > 0 if ENV == "production"`.
+// See expr.go for the syntax. A constraint is not checked when one of the
+// keys it compares is missing or has a value that cannot be read; len() of a
+// missing key is 0.
+type Constraint struct {
+	Expr    string `json:"expr" yaml:"expr"`
+	Name    string `json:"name,omitempty" yaml:"name,omitempty"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// DisplayName returns the name of the constraint, or its expression when it
+// has none. Findings of the constraint use it as their key.
+func (c Constraint) DisplayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Expr
+}
+
+// checkConstraint evaluates c and returns its finding, without a key, when
+// it fails or cannot be evaluated.
+func checkConstraint(c Constraint, ctx exprContext) (Finding, bool) {
+	node, keys, err := parseExpr(c.Expr)
+	if err != nil {
+		return newFinding(CodeConstraintError, fmt.Sprintf("Invalid constraint expression: %v", err), "", c.Expr), true
+	}
+	keys = uniqueStrings(keys)
+
+	ok, err := evalBool(node, ctx)
+	switch {
+	case err == errSkip:
+		return Finding{}, false
+	case err != nil:
+		f := newFinding(CodeConstraintError, fmt.Sprintf("Constraint cannot be evaluated: %v", err), "", c.Expr)
+		f.Keys = keys
+		return f, true
+	case ok:
+		return Finding{}, false
+	}
+
+	var values []string
+	for _, key := range keys {
+		if value, ok := ctx.env[key]; ok {
+			values = append(values, key+"="+value)
+		}
+	}
+	msg := "Constraint failed: " + c.Expr
+	if c.Message != "" {
+		msg = c.Message
+	}
+	f := newFinding(CodeConstraintFailed, msg, c.Expr, strings.Join(values, ", "))
+	f.Keys = keys
+	return f, true
+}
+
+func uniqueStrings(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	var out []string
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,228 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField describes one field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names for min, min+1, … (months and weekdays)
+}
+
+var (
+	cronSecond  = cronField{name: "second", min: 0, max: 59}
+	cronMinute  = cronField{name: "minute", min: 0, max: 59}
+	cronHour    = cronField{name: "hour", min: 0, max: 23}
+	cronDay     = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth   = cronField{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	cronWeekday = cronField{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// cronDescriptors maps the @ shorthands to their expression.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSchedule is a parsed cron expression: the set of values of each field.
+type cronSchedule struct {
+	seconds, minutes, hours, days, months, weekdays []bool
+	// restricted day-of-month and day-of-week fields combine with OR, as in
+	// Vixie cron, when both are restricted.
+	daysRestricted, weekdaysRestricted bool
+}
+
+// parseCron parses a 5-field (minute hour day month weekday) or 6-field
+// (second first) expression, or a descriptor such as @hourly.
+func parseCron(expr string) (cronSchedule, error) {
+	if d, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	} else if strings.HasPrefix(expr, "@") {
+		return cronSchedule{}, fmt.Errorf("unknown descriptor %s", expr)
+	}
+
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return cronSchedule{}, fmt.Errorf("expected 5 or 6 fields but got %d", len(fields))
+	}
+
+	var s cronSchedule
+	var err error
+	targets := []struct {
+		set   *[]bool
+		field cronField
+	}{
+		{&s.seconds, cronSecond}, {&s.minutes, cronMinute}, {&s.hours, cronHour},
+		{&s.days, cronDay}, {&s.months, cronMonth}, {&s.weekdays, cronWeekday},
+	}
+	for i, t := range targets {
+		if *t.set, err = parseCronField(fields[i], t.field); err != nil {
+			return cronSchedule{}, err
+		}
+	}
+	// 7 is Sunday too.
+	s.weekdays[0] = s.weekdays[0] || s.weekdays[7]
+	s.daysRestricted = fields[3] != "*" && fields[3] != "?"
+	s.weekdaysRestricted = fields[5] != "*" && fields[5] != "?"
+	return s, nil
+}
+
+// parseCronField parses a comma-separated list of *, ?, values, ranges
+// (a-b) and steps (*/n, a-b/n, a/n).
+func parseCronField(field string, f cronField) ([]bool, error) {
+	set := make([]bool, f.max+1)
+	for _, item := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid step %q in %s field", stepPart, f.name)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rangePart == "*" || rangePart == "?" && (f.name == cronDay.name || f.name == cronWeekday.name):
+		default:
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = cronValue(from, f); err != nil {
+				return nil, err
+			}
+			switch {
+			case isRange:
+				if hi, err = cronValue(to, f); err != nil {
+					return nil, err
+				}
+				if hi < lo {
+					return nil, fmt.Errorf("invalid range %q in %s field", rangePart, f.name)
+				}
+			case !hasStep:
+				hi = lo
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+func cronValue(s string, f cronField) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field (%d-%d)", s, f.name, f.min, f.max)
+	}
+	return n, nil
+}
+
+func (s cronSchedule) matchesDay(t time.Time) bool {
+	if !s.months[int(t.Month())] {
+		return false
+	}
+	day, weekday := s.days[t.Day()], s.weekdays[int(t.Weekday())]
+	if s.daysRestricted && s.weekdaysRestricted {
+		return day || weekday
+	}
+	return day && weekday
+}
+
+// cronWindowStart and cronWindowDays span 28 years, after which the calendar
+// of weekdays and leap days repeats (within 1901-2099).
+var cronWindowStart = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+const cronWindowDays = 28*365 + 7
+
+// minInterval returns the shortest time between two consecutive runs, in
+// UTC. It fails when the schedule never runs (e.g. on February 30).
+func (s cronSchedule) minInterval() (time.Duration, error) {
+	var times []int // seconds since midnight
+	for h, okH := range s.hours {
+		for m, okM := range s.minutes {
+			for sec, okS := range s.seconds {
+				if okH && okM && okS {
+					times = append(times, h*3600+m*60+sec)
+				}
+			}
+		}
+	}
+	sort.Ints(times)
+
+	var days []int
+	for d := 0; d < cronWindowDays; d++ {
+		if s.matchesDay(cronWindowStart.AddDate(0, 0, d)) {
+			days = append(days, d)
+		}
+	}
+	if len(days) == 0 {
+		return 0, fmt.Errorf("never runs")
+	}
+
+	const day = 24 * 3600
+	span := times[len(times)-1] - times[0]
+	// The gap from the last matching day of the window to the first one of
+	// the next window.
+	min := (days[0]+cronWindowDays-days[len(days)-1])*day - span
+	for i := 1; i < len(times); i++ {
+		if gap := times[i] - times[i-1]; gap < min {
+			min = gap
+		}
+	}
+	for i := 1; i < len(days); i++ {
+		if gap := (days[i]-days[i-1])*day - span; gap < min {
+			min = gap
+		}
+	}
+	return time.Duration(min) * time.Second, nil
+}
+
+// checkCron validates a value of type cron and, when the rule has one, the
+// minimum interval between its runs.
+func checkCron(value string, rule SchemaRule, add addFunc) {
+	s, err := parseCron(value)
+	var interval time.Duration
+	if err == nil {
+		interval, err = s.minInterval()
+	}
+	if err != nil {
+		add(CodeInvalidCron, fmt.Sprintf("Invalid cron expression '%s': %v", value, err), "cron", value)
+		return
+	}
+	if rule.MinInterval == "" {
+		return
+	}
+
+	limit, err := time.ParseDuration(rule.MinInterval)
+	if err != nil {
+		add(CodeInvalidBound, fmt.Sprintf("Invalid minInterval '%s' for type cron", rule.MinInterval), "", rule.MinInterval)
+		return
+	}
+	if interval < limit {
+		add(CodeCronInterval,
+			fmt.Sprintf("Cron expression '%s' runs every %s but the minimum interval is %s", value, interval, limit),
+			">= "+rule.MinInterval, interval.String())
+	}
+}
@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	// Embed the IANA time zone database so timezone values are checked the
+	// same way on machines without /usr/share/zoneinfo (e.g. scratch images).
+	_ "time/tzdata"
+)
+
+// timeLayouts maps the named formats of the datetime type to their layout.
+// Any other format is used as a Go layout.
+var timeLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"DateTime":    time.DateTime,
+}
+
+// checkDate validates a value of type date. format is a Go layout and
+// defaults to 2006-01-02; min and max are written in the same layout.
+func checkDate(value string, rule SchemaRule, add addFunc) {
+	layout := rule.Format
+	if layout == "" {
+		layout = time.DateOnly
+	}
+	checkTime("date", value, layout, rule, CodeInvalidDate, add)
+}
+
+// checkDateTime validates a value of type datetime. format is a Go layout or
+// one of the names in timeLayouts and defaults to RFC3339; min and max are
+// written in the same layout.
+func checkDateTime(value string, rule SchemaRule, add addFunc) {
+	layout := rule.Format
+	if named, ok := timeLayouts[layout]; ok {
+		layout = named
+	} else if layout == "" {
+		layout = time.RFC3339
+	}
+	checkTime("datetime", value, layout, rule, CodeInvalidDateTime, add)
+}
+
+func checkTime(typeName, value, layout string, rule SchemaRule, code string, add addFunc) {
+	parse := func(s string) (float64, error) {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return 0, err
+		}
+		return float64(t.Unix()) + float64(t.Nanosecond())/1e9, nil
+	}
+	format := func(f float64) string {
+		sec := math.Floor(f)
+		return time.Unix(int64(sec), int64((f-sec)*1e9)).UTC().Format(layout)
+	}
+
+	t, err := parse(value)
+	if err != nil {
+		add(code, fmt.Sprintf("Expected %s in format %s but got: %s", typeName, layout, value), layout, value)
+		return
+	}
+
+	checkRange(typeName, value, t, rule, add, parse, format)
+}
+
+// checkTimezone validates a value of type timezone: a name from the IANA
+// time zone database such as Europe/Berlin or UTC.
+func checkTimezone(value string, add addFunc) {
+	// LoadLocation maps "" to UTC and "Local" to the machine's zone; neither
+	// is a zone name.
+	if value == "" || value == "Local" {
+		add(CodeInvalidTimezone, fmt.Sprintf("Expected IANA time zone (e.g. Europe/Berlin) but got: %s", value), "timezone", value)
+		return
+	}
+	if _, err := time.LoadLocation(value); err != nil {
+		add(CodeInvalidTimezone, fmt.Sprintf("Expected IANA time zone (e.g. Europe/Berlin) but got: %s", value), "timezone", value)
+	}
+}
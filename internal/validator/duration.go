@@ -0,0 +1,28 @@
+package validator
+
+import (
+	"fmt"
+	"time"
+)
+
+// checkDuration validates a value of type duration, written as accepted by
+// Go's time.ParseDuration ("30s", "5m", "1h30m"). min and max are durations
+// too.
+func checkDuration(value string, rule SchemaRule, add addFunc) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		add(CodeInvalidDuration, fmt.Sprintf("Expected duration (e.g. 30s, 5m, 1h30m) but got: %s", value), "duration", value)
+		return
+	}
+
+	checkRange("duration", value, float64(d), rule, add, parseDurationBound, formatDuration)
+}
+
+func parseDurationBound(s string) (float64, error) {
+	d, err := time.ParseDuration(s)
+	return float64(d), err
+}
+
+func formatDuration(f float64) string {
+	return time.Duration(f).String()
+}
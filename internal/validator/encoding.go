@@ -0,0 +1,157 @@
+package validator
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// base64Encodings maps the formats of the base64 type to their encoding.
+var base64Encodings = map[string]*base64.Encoding{
+	"std":    base64.StdEncoding,
+	"url":    base64.URLEncoding,
+	"rawstd": base64.RawStdEncoding,
+	"rawurl": base64.RawURLEncoding,
+}
+
+// Values of the base64, hex and jwt types are usually secrets, so their
+// findings never include the value itself.
+
+// checkBase64 validates a value of type base64. Without a format any of the
+// standard or URL-safe alphabets, padded or not, is accepted. length,
+// minLength and maxLength apply to the decoded bytes.
+func checkBase64(value string, rule SchemaRule, add addFunc) {
+	if _, ok := base64Encodings[rule.Format]; rule.Format != "" && !ok {
+		add(CodeUnknownFormat, fmt.Sprintf("Unknown base64 format '%s' — skipping check", rule.Format), "", rule.Format)
+		return
+	}
+
+	formats := []string{"std", "url", "rawstd", "rawurl"}
+	if rule.Format != "" {
+		formats = []string{rule.Format}
+	}
+
+	for _, format := range formats {
+		if b, err := base64Encodings[format].DecodeString(value); err == nil {
+			checkByteLength(len(b), rule, add)
+			return
+		}
+	}
+
+	expected := "base64"
+	if rule.Format != "" {
+		expected = fmt.Sprintf("base64 (%s)", rule.Format)
+	}
+	add(CodeInvalidBase64, fmt.Sprintf("Expected %s-encoded value", expected), expected, "")
+}
+
+// checkHex validates a value of type hex: an even number of hexadecimal
+// digits. length, minLength and maxLength apply to the decoded bytes.
+func checkHex(value string, rule SchemaRule, add addFunc) {
+	b, err := hex.DecodeString(value)
+	if err != nil {
+		add(CodeInvalidHex, "Expected hex-encoded value", "hex", "")
+		return
+	}
+	checkByteLength(len(b), rule, add)
+}
+
+// checkByteLength checks the decoded length of a base64 or hex value.
+func checkByteLength(n int, rule SchemaRule, add addFunc) {
+	actual := fmt.Sprintf("%d bytes", n)
+	if rule.Length != nil && n != *rule.Length {
+		add(CodeLength, fmt.Sprintf("Expected %d decoded bytes but got: %d", *rule.Length, n),
+			fmt.Sprintf("%d bytes", *rule.Length), actual)
+	}
+	if rule.MaxLength != nil && n > *rule.MaxLength {
+		add(CodeMaxLength, fmt.Sprintf("Expected at most %d decoded bytes but got: %d", *rule.MaxLength, n),
+			fmt.Sprintf("<= %d bytes", *rule.MaxLength), actual)
+	}
+	if rule.MinLength != nil && n < *rule.MinLength {
+		add(CodeMinLength, fmt.Sprintf("Expected at least %d decoded bytes but got: %d", *rule.MinLength, n),
+			fmt.Sprintf(">= %d bytes", *rule.MinLength), actual)
+	}
+}
+
+// checkUUID validates a value of type uuid in its canonical 8-4-4-4-12 form,
+// in either case. version (1-8) also requires that version and the RFC 4122
+// variant.
+func checkUUID(value string, rule SchemaRule, add addFunc) {
+	if rule.Version < 0 || rule.Version > 8 {
+		add(CodeUnknownFormat, fmt.Sprintf("Unknown UUID version %d — expected 1 to 8", rule.Version), "", fmt.Sprint(rule.Version))
+		return
+	}
+
+	if !isUUID(value) {
+		add(CodeInvalidUUID, fmt.Sprintf("Expected UUID but got: %s", value), "uuid", value)
+		return
+	}
+	if rule.Version == 0 {
+		return
+	}
+
+	version := strings.IndexByte("0123456789abcdef", lowerHex(value[14]))
+	variant := strings.IndexByte("0123456789abcdef", lowerHex(value[19]))
+	if version != rule.Version || variant&0xc != 0x8 {
+		add(CodeUUIDVersion, fmt.Sprintf("Expected UUID version %d but got: %s", rule.Version, value),
+			fmt.Sprintf("version %d", rule.Version), value)
+	}
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdef", rune(lowerHex(s[i]))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func lowerHex(c byte) byte {
+	if c >= 'A' && c <= 'F' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// checkJWT validates the structure of a value of type jwt: three base64url
+// segments without padding, of which the header and the payload decode to
+// JSON objects. The signature is not verified.
+func checkJWT(value string, add addFunc) {
+	if reason := jwtError(value); reason != "" {
+		add(CodeInvalidJWT, fmt.Sprintf("Expected JWT but got a token that %s", reason), "jwt", "")
+	}
+}
+
+func jwtError(value string) string {
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 {
+		return fmt.Sprintf("has %d segments instead of 3", len(parts))
+	}
+	for i, name := range []string{"header", "payload"} {
+		b, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			return fmt.Sprintf("has a %s that is not base64url", name)
+		}
+		var obj map[string]interface{}
+		if err := json.Unmarshal(b, &obj); err != nil {
+			return fmt.Sprintf("has a %s that is not a JSON object", name)
+		}
+	}
+	if _, err := base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return "has a signature that is not base64url"
+	}
+	return ""
+}
@@ -0,0 +1,526 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// This file implements the expression language of schema constraints:
+//
+//	constraint = or [ "if" or ]
+//	or         = and { "||" and }
+//	and        = not { "&&" not }
+//	not        = "!" not | compare
+//	compare    = operand [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) operand ]
+//	operand    = "(" or ")" | "len(" operand ")" | KEY | literal
+//
+// Literals are numbers, double-quoted strings, true and false. A number may
+// carry a unit ("30s", "64MiB") and is then read with the type of the key it
+// is compared with, as are strings compared with typed keys ("2024-01-01",
+// "1.2.0").
+
+// errSkip is returned when a key of the expression is missing or its value
+// cannot be read; the constraint is then not checked.
+var errSkip = errors.New("value not available")
+
+type exprKind int
+
+const (
+	kindString exprKind = iota
+	kindNumber
+	kindBool
+	kindVersion
+)
+
+var exprKindNames = map[exprKind]string{
+	kindString: "string", kindNumber: "number", kindBool: "boolean", kindVersion: "version",
+}
+
+// exprValue is the result of evaluating an expression. Literals keep their
+// source text so they can be read again with the type of a key.
+type exprValue struct {
+	kind exprKind
+	num  float64
+	str  string
+	b    bool
+	ver  semver
+
+	// unit is "duration", "bytesize" or "time" for numbers that are read
+	// from a value with a unit, and empty for plain numbers. Numbers of
+	// different units cannot be compared.
+	unit string
+
+	// rule is the schema rule of a key value; literal marks literals.
+	rule    SchemaRule
+	literal bool
+	raw     string
+}
+
+// exprContext holds the values and rules an expression is evaluated with.
+type exprContext struct {
+	env   map[string]string
+	rules map[string]SchemaRule
+}
+
+type exprNode interface {
+	eval(ctx exprContext) (exprValue, error)
+}
+
+type (
+	orNode  struct{ l, r exprNode }
+	andNode struct{ l, r exprNode }
+	notNode struct{ x exprNode }
+	ifNode  struct{ then, cond exprNode }
+	lenNode struct{ x exprNode }
+	keyNode struct{ name string }
+	litNode struct {
+		raw    string
+		quoted bool
+	}
+	cmpNode struct {
+		op   string
+		l, r exprNode
+	}
+)
+
+func evalBool(n exprNode, ctx exprContext) (bool, error) {
+	v, err := n.eval(ctx)
+	if err != nil {
+		return false, err
+	}
+	if v.kind != kindBool {
+		return false, fmt.Errorf("expected a condition but got a %s", exprKindNames[v.kind])
+	}
+	return v.b, nil
+}
+
+func boolValue(b bool) exprValue { return exprValue{kind: kindBool, b: b} }
+
+func (n orNode) eval(ctx exprContext) (exprValue, error) {
+	l, err := evalBool(n.l, ctx)
+	if err != nil || l {
+		return boolValue(l), err
+	}
+	r, err := evalBool(n.r, ctx)
+	return boolValue(r), err
+}
+
+func (n andNode) eval(ctx exprContext) (exprValue, error) {
+	l, err := evalBool(n.l, ctx)
+	if err != nil || !l {
+		return boolValue(l), err
+	}
+	r, err := evalBool(n.r, ctx)
+	return boolValue(r), err
+}
+
+func (n notNode) eval(ctx exprContext) (exprValue, error) {
+	x, err := evalBool(n.x, ctx)
+	return boolValue(!x), err
+}
+
+// eval of "A if B" is true when B is false.
+func (n ifNode) eval(ctx exprContext) (exprValue, error) {
+	cond, err := evalBool(n.cond, ctx)
+	if err != nil || !cond {
+		return boolValue(true), err
+	}
+	then, err := evalBool(n.then, ctx)
+	return boolValue(then), err
+}
+
+// eval of len is the number of items of a list key and the number of
+// characters of anything else. A missing key has length 0, so
+// "len(KEY) > 0" requires KEY to be set.
+func (n lenNode) eval(ctx exprContext) (exprValue, error) {
+	var v exprValue
+	if key, ok := n.x.(keyNode); ok {
+		v = exprValue{rule: ctx.rules[key.name], raw: ctx.env[key.name]}
+	} else {
+		var err error
+		if v, err = n.x.eval(ctx); err != nil {
+			return exprValue{}, err
+		}
+	}
+	count := utf8.RuneCountInString(v.raw)
+	if v.rule.Type == "list" {
+		count = len(listItems(v.raw, v.rule))
+	}
+	return exprValue{kind: kindNumber, num: float64(count), rule: SchemaRule{Type: "integer"}}, nil
+}
+
+func (n keyNode) eval(ctx exprContext) (exprValue, error) {
+	raw, ok := ctx.env[n.name]
+	if !ok {
+		return exprValue{}, errSkip
+	}
+	v, err := typedValue(ctx.rules[n.name], raw)
+	if err != nil {
+		return exprValue{}, errSkip
+	}
+	return v, nil
+}
+
+func (n litNode) eval(exprContext) (exprValue, error) {
+	v := exprValue{kind: kindString, str: n.raw, raw: n.raw, literal: true}
+	if n.quoted {
+		return v, nil
+	}
+	switch n.raw {
+	case "true", "false":
+		return exprValue{kind: kindBool, b: n.raw == "true", raw: n.raw, literal: true}, nil
+	}
+	if f, err := strconv.ParseFloat(n.raw, 64); err == nil {
+		return exprValue{kind: kindNumber, num: f, raw: n.raw, literal: true}, nil
+	}
+	return v, nil
+}
+
+func (n cmpNode) eval(ctx exprContext) (exprValue, error) {
+	l, err := n.l.eval(ctx)
+	if err != nil {
+		return exprValue{}, err
+	}
+	r, err := n.r.eval(ctx)
+	if err != nil {
+		return exprValue{}, err
+	}
+
+	// Read a literal with the type of the key on the other side.
+	if l.literal && !r.literal {
+		if l, err = coerceLiteral(l, r.rule); err != nil {
+			return exprValue{}, err
+		}
+	} else if r.literal && !l.literal {
+		if r, err = coerceLiteral(r, l.rule); err != nil {
+			return exprValue{}, err
+		}
+	}
+	if l.kind != r.kind || l.unit != r.unit {
+		return exprValue{}, fmt.Errorf("cannot compare %s with %s", l.typeName(), r.typeName())
+	}
+
+	var cmp int
+	switch l.kind {
+	case kindNumber:
+		cmp = compareFloat(l.num, r.num)
+	case kindString:
+		cmp = strings.Compare(l.str, r.str)
+	case kindVersion:
+		cmp = l.ver.compare(r.ver)
+	case kindBool:
+		if n.op != "==" && n.op != "!=" {
+			return exprValue{}, fmt.Errorf("cannot order booleans with %s", n.op)
+		}
+		if l.b != r.b {
+			cmp = 1
+		}
+	}
+
+	switch n.op {
+	case "==":
+		return boolValue(cmp == 0), nil
+	case "!=":
+		return boolValue(cmp != 0), nil
+	case "<":
+		return boolValue(cmp < 0), nil
+	case "<=":
+		return boolValue(cmp <= 0), nil
+	case ">":
+		return boolValue(cmp > 0), nil
+	default:
+		return boolValue(cmp >= 0), nil
+	}
+}
+
+// typeName describes v for error messages: the type of its key, or the kind
+// of a literal.
+func (v exprValue) typeName() string {
+	if v.rule.Type != "" {
+		return v.rule.Type
+	}
+	return exprKindNames[v.kind]
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func coerceLiteral(lit exprValue, rule SchemaRule) (exprValue, error) {
+	v, err := typedValue(rule, lit.raw)
+	if err != nil {
+		return exprValue{}, fmt.Errorf("cannot read %q as %s", lit.raw, typeOrString(rule))
+	}
+	return v, nil
+}
+
+func typeOrString(rule SchemaRule) string {
+	if rule.Type == "" {
+		return "string"
+	}
+	return rule.Type
+}
+
+// typedValue reads raw as a value of the rule's type. Durations compare in
+// nanoseconds, byte sizes in bytes and dates in seconds; types without an
+// order of their own compare as strings.
+func typedValue(rule SchemaRule, raw string) (exprValue, error) {
+	v := exprValue{rule: rule, raw: raw}
+	var err error
+	switch rule.Type {
+	case "number", "integer", "port":
+		v.kind = kindNumber
+		v.num, err = strconv.ParseFloat(raw, 64)
+	case "duration":
+		v.kind = kindNumber
+		v.unit = "duration"
+		v.num, err = parseDurationBound(raw)
+	case "bytesize":
+		v.kind = kindNumber
+		v.unit = "bytesize"
+		v.num, err = parseByteSize(raw)
+	case "boolean":
+		v.kind = kindBool
+		switch strings.ToLower(raw) {
+		case "true":
+			v.b = true
+		case "false":
+		default:
+			err = fmt.Errorf("not a boolean")
+		}
+	case "date", "datetime":
+		layout := rule.Format
+		if named, ok := timeLayouts[layout]; ok && rule.Type == "datetime" {
+			layout = named
+		} else if layout == "" && rule.Type == "date" {
+			layout = time.DateOnly
+		} else if layout == "" {
+			layout = time.RFC3339
+		}
+		var t time.Time
+		t, err = time.Parse(layout, raw)
+		v.kind = kindNumber
+		v.unit = "time"
+		v.num = float64(t.Unix()) + float64(t.Nanosecond())/1e9
+	case "semver":
+		v.kind = kindVersion
+		v.ver, err = parseSemver(raw)
+	default:
+		v.kind = kindString
+		v.str = raw
+	}
+	return v, err
+}
+
+// parseExpr parses a constraint expression and returns it with the keys it
+// refers to, in order of appearance.
+func parseExpr(src string) (exprNode, []string, error) {
+	tokens, err := lexExpr(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.peek() == "if" {
+		p.pos++
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, nil, err
+		}
+		n = ifNode{then: n, cond: cond}
+	}
+	if p.pos < len(p.tokens) {
+		return nil, nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return n, p.keys, nil
+}
+
+var exprOperators = map[string]bool{"==": true, "!=": true, "<=": true, ">=": true, "&&": true, "||": true}
+
+type exprToken struct {
+	text   string
+	quoted bool
+}
+
+// lexExpr splits src into operators, parentheses, words (keys, numbers with
+// units, true, false, if, len) and quoted strings.
+func lexExpr(src string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			s, err := strconv.Unquote(src[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", src[i:j+1])
+			}
+			tokens = append(tokens, exprToken{text: s, quoted: true})
+			i = j + 1
+		case strings.ContainsRune("()", rune(c)):
+			tokens = append(tokens, exprToken{text: string(c)})
+			i++
+		case strings.ContainsRune("=!<>&|", rune(c)):
+			op := string(c)
+			if i+1 < len(src) && exprOperators[src[i:i+2]] {
+				op = src[i : i+2]
+			}
+			if op == "=" || op == "&" || op == "|" {
+				return nil, fmt.Errorf("unknown operator %q", op)
+			}
+			tokens = append(tokens, exprToken{text: op})
+			i += len(op)
+		case isWordByte(c) || c == '-' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			j := i + 1
+			for j < len(src) && (isWordByte(src[j]) || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, exprToken{text: src[i:j]})
+			i = j
+		default:
+			r, _ := utf8.DecodeRuneInString(src[i:])
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c < utf8.RuneSelf && (unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)))
+}
+
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+	keys   []string
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].quoted {
+		return p.tokens[p.pos].text
+	}
+	return ""
+}
+
+func (p *exprParser) expect(text string) error {
+	if p.peek() != text {
+		if p.pos >= len(p.tokens) {
+			return fmt.Errorf("expected %q at end of expression", text)
+		}
+		return fmt.Errorf("expected %q but got %q", text, p.tokens[p.pos].text)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	n, err := p.parseAnd()
+	for err == nil && p.peek() == "||" {
+		p.pos++
+		var r exprNode
+		if r, err = p.parseAnd(); err == nil {
+			n = orNode{n, r}
+		}
+	}
+	return n, err
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	n, err := p.parseNot()
+	for err == nil && p.peek() == "&&" {
+		p.pos++
+		var r exprNode
+		if r, err = p.parseNot(); err == nil {
+			n = andNode{n, r}
+		}
+	}
+	return n, err
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	if p.peek() == "!" {
+		p.pos++
+		x, err := p.parseNot()
+		return notNode{x}, err
+	}
+	return p.parseCompare()
+}
+
+func (p *exprParser) parseCompare() (exprNode, error) {
+	l, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	switch op := p.peek(); op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.pos++
+		r, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return cmpNode{op: op, l: l, r: r}, nil
+	}
+	return l, nil
+}
+
+func (p *exprParser) parseOperand() (exprNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	if tok.quoted {
+		return litNode{raw: tok.text, quoted: true}, nil
+	}
+	switch {
+	case tok.text == "(":
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(")")
+	case tok.text == "len" && p.peek() == "(":
+		p.pos++
+		x, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return lenNode{x}, p.expect(")")
+	case tok.text == "true" || tok.text == "false":
+		return litNode{raw: tok.text}, nil
+	case tok.text == "if":
+		return nil, fmt.Errorf("unexpected \"if\"")
+	case tok.text[0] == '-' || tok.text[0] >= '0' && tok.text[0] <= '9':
+		return litNode{raw: tok.text}, nil
+	case isWordByte(tok.text[0]) && !strings.Contains(tok.text, "."):
+		p.keys = append(p.keys, tok.text)
+		return keyNode{name: tok.text}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", tok.text)
+}
@@ -0,0 +1,204 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GroupsKey is the reserved schema key that holds the key groups.
+const GroupsKey = "$groups"
+
+// Group constrains which keys of a set may be present together. Each
+// member is a single key or a set of keys that belong together, e.g.
+// DB_HOST+DB_USER+DB_NAME. A key is present when it is set to a non-empty
+// value in the .env file; defaults do not count.
+//
+//   - oneOf: exactly one member is present, and no other member is partly
+//     present.
+//   - anyOf: at least one member is fully present.
+//   - exclusive: at most one member is (partly) present.
+//   - allOrNone: either every key of the group is present or none is.
+type Group struct {
+	Type    string        `json:"type" yaml:"type"`
+	Keys    []GroupMember `json:"keys" yaml:"keys"`
+	Name    string        `json:"name,omitempty" yaml:"name,omitempty"`
+	Message string        `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// groupCodes maps the group types to the code of their finding.
+var groupCodes = map[string]string{
+	"oneOf":     CodeGroupOneOf,
+	"anyOf":     CodeGroupAnyOf,
+	"exclusive": CodeGroupExclusive,
+	"allOrNone": CodeGroupAllOrNone,
+}
+
+// GroupMember is one member of a group: a key, or a list of keys that only
+// count as present together. In a schema file it is written as a string or
+// a list of strings.
+type GroupMember []string
+
+func (m *GroupMember) UnmarshalJSON(data []byte) error {
+	var key string
+	if err := json.Unmarshal(data, &key); err == nil {
+		*m = GroupMember{key}
+		return nil
+	}
+	var keys []string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("group member must be a key or a list of keys, got %s", data)
+	}
+	*m = keys
+	return nil
+}
+
+func (m GroupMember) MarshalJSON() ([]byte, error) {
+	if len(m) == 1 {
+		return json.Marshal(m[0])
+	}
+	return json.Marshal([]string(m))
+}
+
+func (m *GroupMember) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*m = GroupMember{node.Value}
+		return nil
+	}
+	var keys []string
+	if err := node.Decode(&keys); err != nil {
+		return fmt.Errorf("group member must be a key or a list of keys")
+	}
+	*m = keys
+	return nil
+}
+
+func (m GroupMember) MarshalYAML() (interface{}, error) {
+	if len(m) == 1 {
+		return m[0], nil
+	}
+	return []string(m), nil
+}
+
+func (m GroupMember) String() string {
+	return strings.Join(m, "+")
+}
+
+// DisplayName returns the name of the group, or a description such as
+// "oneOf(DATABASE_URL, DB_HOST+DB_USER+DB_NAME)" when it has none. Findings
+// of the group use it as their key.
+func (g Group) DisplayName() string {
+	if g.Name != "" {
+		return g.Name
+	}
+	return fmt.Sprintf("%s(%s)", g.Type, g.members())
+}
+
+func (g Group) members() string {
+	names := make([]string, len(g.Keys))
+	for i, m := range g.Keys {
+		names[i] = m.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// allKeys returns every key of the group in declaration order.
+func (g Group) allKeys() []string {
+	var keys []string
+	for _, m := range g.Keys {
+		keys = append(keys, m...)
+	}
+	return keys
+}
+
+func (g Group) validate() error {
+	if _, ok := groupCodes[g.Type]; !ok {
+		return fmt.Errorf("unknown group type %q (expected oneOf, anyOf, exclusive or allOrNone)", g.Type)
+	}
+	if len(g.allKeys()) < 2 {
+		return fmt.Errorf("group %s needs at least two keys", g.DisplayName())
+	}
+	for _, m := range g.Keys {
+		if len(m) == 0 {
+			return fmt.Errorf("group %s has an empty member", g.DisplayName())
+		}
+	}
+	return nil
+}
+
+// checkGroup returns the finding of a group whose constraint does not hold,
+// given the keys that are present. The finding has no key yet.
+func checkGroup(g Group, present map[string]bool) (Finding, bool) {
+	var complete, partial, touched []string
+	for _, m := range g.Keys {
+		n := 0
+		for _, key := range m {
+			if present[key] {
+				n++
+			}
+		}
+		switch {
+		case n == len(m):
+			complete = append(complete, m.String())
+			touched = append(touched, m.String())
+		case n > 0:
+			partial = append(partial, fmt.Sprintf("%s (missing %s)", m.String(), strings.Join(missingKeys(m, present), ", ")))
+			touched = append(touched, m.String())
+		}
+	}
+
+	var msg string
+	switch g.Type {
+	case "oneOf":
+		switch {
+		case len(complete) == 0 && len(partial) == 0:
+			msg = fmt.Sprintf("Expected exactly one of %s but none is set", g.members())
+		case len(touched) > 1:
+			msg = fmt.Sprintf("Expected exactly one of %s but got: %s", g.members(), strings.Join(touched, ", "))
+		case len(partial) > 0:
+			msg = fmt.Sprintf("Expected exactly one of %s but %s is incomplete", g.members(), partial[0])
+		}
+	case "anyOf":
+		if len(complete) == 0 {
+			msg = fmt.Sprintf("Expected at least one of %s but none is set", g.members())
+			if len(partial) > 0 {
+				msg = fmt.Sprintf("Expected at least one of %s but %s is incomplete", g.members(), strings.Join(partial, ", "))
+			}
+		}
+	case "exclusive":
+		if len(touched) > 1 {
+			msg = fmt.Sprintf("Expected at most one of %s but got: %s", g.members(), strings.Join(touched, ", "))
+		}
+	case "allOrNone":
+		keys := g.allKeys()
+		if missing := missingKeys(keys, present); len(missing) > 0 && len(missing) < len(keys) {
+			verb := "is"
+			if len(missing) > 1 {
+				verb = "are"
+			}
+			msg = fmt.Sprintf("Expected all or none of %s but %s %s not set", strings.Join(keys, ", "), strings.Join(missing, ", "), verb)
+		}
+	}
+	if msg == "" {
+		return Finding{}, false
+	}
+	if g.Message != "" {
+		msg = g.Message
+	}
+
+	f := newFinding(groupCodes[g.Type], msg, g.Type+" "+g.members(), strings.Join(touched, ", "))
+	f.Keys = g.allKeys()
+	return f, true
+}
+
+func missingKeys(keys []string, present map[string]bool) []string {
+	var missing []string
+	for _, key := range keys {
+		if !present[key] {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,55 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// integerBits maps the formats of the integer type to their bit size.
+var integerBits = map[string]int{
+	"int": 64, "int8": 8, "int16": 16, "int32": 32, "int64": 64,
+	"uint": 64, "uint8": 8, "uint16": 16, "uint32": 32, "uint64": 64,
+}
+
+// parseInteger parses value as a base-10 integer that fits format, which is
+// one of the keys of integerBits.
+func parseInteger(value, format string) (float64, error) {
+	bits := integerBits[format]
+
+	if strings.HasPrefix(format, "uint") {
+		n, err := strconv.ParseUint(value, 10, bits)
+		return float64(n), err
+	}
+	n, err := strconv.ParseInt(value, 10, bits)
+	return float64(n), err
+}
+
+// checkInteger validates a value of type integer: a whole number that fits
+// the optional format (int8 … int64, uint8 … uint64) and lies within min/max.
+func checkInteger(value string, rule SchemaRule, add addFunc) {
+	if _, ok := integerBits[rule.Format]; rule.Format != "" && !ok {
+		add(CodeUnknownFormat, fmt.Sprintf("Unknown integer format '%s' — skipping check", rule.Format), "", rule.Format)
+		return
+	}
+
+	format := rule.Format
+	if format == "" {
+		format = "int64"
+	}
+
+	num, err := parseInteger(value, format)
+	if err != nil {
+		// Negative values are whole numbers too; they just don't fit uints.
+		_, signedErr := strconv.ParseInt(value, 10, 64)
+		if errors.Is(err, strconv.ErrRange) || signedErr == nil {
+			add(CodeIntegerRange, fmt.Sprintf("Value %s does not fit in %s", value, format), format, value)
+			return
+		}
+		add(CodeNotInteger, fmt.Sprintf("Expected integer but got: %s", value), "integer", value)
+		return
+	}
+
+	checkRange("integer", value, num, rule, add, parseFloat, formatFloat)
+}
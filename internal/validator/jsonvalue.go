@@ -0,0 +1,235 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// checkJSON validates a value of type json: it must parse as JSON and, when
+// the rule has a schema, match that JSON Schema fragment. Each violation is
+// a separate finding naming the offending path, e.g. $.routes[0].port.
+func checkJSON(value string, rule SchemaRule, add addFunc) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(value), &doc); err != nil {
+		add(CodeInvalidJSON, fmt.Sprintf("Invalid JSON: %v", err), "json", "")
+		return
+	}
+	if rule.JSONSchema != nil {
+		checkJSONSchema("$", doc, rule.JSONSchema, add)
+	}
+}
+
+// checkJSONSchema checks v against a JSON Schema fragment. Only a subset of
+// JSON Schema is supported: type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum, minLength, maxLength and pattern.
+// Other keywords are ignored.
+func checkJSONSchema(path string, v interface{}, schema map[string]interface{}, add addFunc) {
+	violation := func(expected, format string, args ...interface{}) {
+		add(CodeJSONSchema, path+": "+fmt.Sprintf(format, args...), expected, "")
+	}
+
+	if t, ok := schema["type"]; ok {
+		types := jsonTypes(t)
+		if !jsonTypeMatches(v, types) {
+			violation(strings.Join(types, " or "), "expected %s but got %s", strings.Join(types, " or "), jsonTypeOf(v))
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(normalizeJSON(e), v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			violation(fmt.Sprintf("one of %v", enum), "value is not one of %v", enum)
+		}
+	}
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(normalizeJSON(c), v) {
+		violation(fmt.Sprintf("%v", c), "value must be %v", c)
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		checkJSONObject(path, v, schema, add, violation)
+
+	case []interface{}:
+		if n, ok := jsonNumber(schema["minItems"]); ok && float64(len(v)) < n {
+			violation(fmt.Sprintf(">= %v items", n), "expected at least %v items but got %d", n, len(v))
+		}
+		if n, ok := jsonNumber(schema["maxItems"]); ok && float64(len(v)) > n {
+			violation(fmt.Sprintf("<= %v items", n), "expected at most %v items but got %d", n, len(v))
+		}
+		if items, ok := jsonSchemaMap(schema["items"]); ok {
+			for i, item := range v {
+				checkJSONSchema(fmt.Sprintf("%s[%d]", path, i), item, items, add)
+			}
+		}
+
+	case float64:
+		if n, ok := jsonNumber(schema["minimum"]); ok && v < n {
+			violation(fmt.Sprintf(">= %v", n), "expected >= %v but got %v", n, v)
+		}
+		if n, ok := jsonNumber(schema["maximum"]); ok && v > n {
+			violation(fmt.Sprintf("<= %v", n), "expected <= %v but got %v", n, v)
+		}
+		if n, ok := jsonNumber(schema["exclusiveMinimum"]); ok && v <= n {
+			violation(fmt.Sprintf("> %v", n), "expected > %v but got %v", n, v)
+		}
+		if n, ok := jsonNumber(schema["exclusiveMaximum"]); ok && v >= n {
+			violation(fmt.Sprintf("< %v", n), "expected < %v but got %v", n, v)
+		}
+
+	case string:
+		length := utf8.RuneCountInString(v)
+		if n, ok := jsonNumber(schema["minLength"]); ok && float64(length) < n {
+			violation(fmt.Sprintf("length >= %v", n), "expected length >= %v but got %d", n, length)
+		}
+		if n, ok := jsonNumber(schema["maxLength"]); ok && float64(length) > n {
+			violation(fmt.Sprintf("length <= %v", n), "expected length <= %v but got %d", n, length)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if matched, err := regexp.MatchString(pattern, v); err != nil {
+				add(CodeInvalidPattern, fmt.Sprintf("%s: invalid regex pattern: %s", path, pattern), "", pattern)
+			} else if !matched {
+				violation(pattern, "value does not match pattern: %s", pattern)
+			}
+		}
+	}
+}
+
+func checkJSONObject(path string, obj map[string]interface{}, schema map[string]interface{}, add addFunc, violation func(string, string, ...interface{})) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, r := range required {
+			name := fmt.Sprintf("%v", r)
+			if _, ok := obj[name]; !ok {
+				violation(name, "missing required property %q", name)
+			}
+		}
+	}
+
+	properties, _ := jsonSchemaMap(schema["properties"])
+	additional, hasAdditional := schema["additionalProperties"]
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		child := path + "." + k
+		if prop, ok := jsonSchemaMap(properties[k]); ok {
+			checkJSONSchema(child, obj[k], prop, add)
+			continue
+		}
+		if _, declared := properties[k]; declared || !hasAdditional {
+			continue
+		}
+		if allowed, ok := additional.(bool); ok && !allowed {
+			violation("no additional properties", "property %q is not allowed", k)
+		} else if sub, ok := jsonSchemaMap(additional); ok {
+			checkJSONSchema(child, obj[k], sub, add)
+		}
+	}
+}
+
+// jsonTypes returns the type keyword of a schema, which is a single type
+// name or a list of them.
+func jsonTypes(t interface{}) []string {
+	if list, ok := t.([]interface{}); ok {
+		types := make([]string, len(list))
+		for i, item := range list {
+			types[i] = fmt.Sprintf("%v", item)
+		}
+		return types
+	}
+	return []string{fmt.Sprintf("%v", t)}
+}
+
+func jsonTypeMatches(v interface{}, types []string) bool {
+	actual := jsonTypeOf(v)
+	for _, t := range types {
+		if t == actual || t == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeOf returns the JSON Schema type name of a decoded value. Whole
+// numbers are reported as integer.
+func jsonTypeOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// jsonNumber reads a numeric schema keyword, which is a float64 when the
+// schema file is JSON and an int when it is YAML.
+func jsonNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func jsonSchemaMap(v interface{}) (map[string]interface{}, bool) {
+	m, ok := v.(map[string]interface{})
+	return m, ok
+}
+
+// normalizeJSON converts the numbers of a schema value (see jsonNumber) to
+// float64 so it compares equal to the decoded JSON value.
+func normalizeJSON(v interface{}) interface{} {
+	if n, ok := jsonNumber(v); ok {
+		return n
+	}
+	switch v := v.(type) {
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalizeJSON(item)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = normalizeJSON(item)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,61 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// listItems splits a value of type list on the rule's separator (a comma by
+// default) and trims the spaces around each item. An empty value is an empty
+// list.
+func listItems(value string, rule SchemaRule) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	sep := rule.Separator
+	if sep == "" {
+		sep = ","
+	}
+	items := strings.Split(value, sep)
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items
+}
+
+// checkList validates a value of type list: its number of items, whether
+// they are unique and, when the rule has items, every item against that
+// rule. Findings of an item keep its code and name the item by position.
+func checkList(value string, rule SchemaRule, opts Options, add addFunc) {
+	items := listItems(value, rule)
+	count := fmt.Sprintf("%d items", len(items))
+
+	if rule.MinItems != nil && len(items) < *rule.MinItems {
+		add(CodeMinItems, fmt.Sprintf("Expected at least %d items but got: %d", *rule.MinItems, len(items)),
+			fmt.Sprintf(">= %d items", *rule.MinItems), count)
+	}
+	if rule.MaxItems != nil && len(items) > *rule.MaxItems {
+		add(CodeMaxItems, fmt.Sprintf("Expected at most %d items but got: %d", *rule.MaxItems, len(items)),
+			fmt.Sprintf("<= %d items", *rule.MaxItems), count)
+	}
+
+	if rule.Unique {
+		seen := make(map[string]int, len(items))
+		for i, item := range items {
+			if first, ok := seen[item]; ok {
+				add(CodeDuplicateItem, fmt.Sprintf("Item %d duplicates item %d: %s", i+1, first+1, item), "unique items", item)
+				continue
+			}
+			seen[item] = i
+		}
+	}
+
+	if rule.Items == nil {
+		return
+	}
+	for i, item := range items {
+		for _, f := range checkValue(item, *rule.Items, opts) {
+			add(f.Code, fmt.Sprintf("Item %d: %s", i+1, f.Message), f.Expected, f.Actual)
+		}
+	}
+}
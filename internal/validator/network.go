@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"strings"
+)
+
+// checkEmail validates a value of type email: a bare address such as
+// ops@example.com, without a display name or angle brackets.
+func checkEmail(value string, add addFunc) {
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Name != "" || addr.Address != value {
+		add(CodeInvalidEmail, fmt.Sprintf("Expected email address but got: %s", value), "email", value)
+	}
+}
+
+// checkHostname validates a value of type hostname following RFC 1123: dot
+// separated labels of 1-63 letters, digits and hyphens that do not start or
+// end with a hyphen, 253 characters at most. A trailing dot is allowed.
+func checkHostname(value string, add addFunc) {
+	if !isHostname(value) {
+		add(CodeInvalidHostname, fmt.Sprintf("Expected hostname but got: %s", value), "hostname", value)
+	}
+}
+
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// checkIP validates a value of type ip. version restricts it to IPv4 (4) or
+// IPv6 (6); IPv4-mapped IPv6 addresses count as IPv6.
+func checkIP(value string, rule SchemaRule, add addFunc) {
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		add(CodeInvalidIP, fmt.Sprintf("Expected IP address but got: %s", value), "ip", value)
+		return
+	}
+	checkIPVersion(value, addr, rule, add)
+}
+
+// checkCIDR validates a value of type cidr: an IP prefix such as 10.0.0.0/8
+// or fd00::/8. version works as for the ip type.
+func checkCIDR(value string, rule SchemaRule, add addFunc) {
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		add(CodeInvalidCIDR, fmt.Sprintf("Expected CIDR block (e.g. 10.0.0.0/8) but got: %s", value), "cidr", value)
+		return
+	}
+	checkIPVersion(value, prefix.Addr(), rule, add)
+}
+
+func checkIPVersion(value string, addr netip.Addr, rule SchemaRule, add addFunc) {
+	switch rule.Version {
+	case 0:
+	case 4:
+		if !addr.Is4() {
+			add(CodeIPVersion, fmt.Sprintf("Expected IPv4 but got: %s", value), "IPv4", value)
+		}
+	case 6:
+		if !addr.Is6() {
+			add(CodeIPVersion, fmt.Sprintf("Expected IPv6 but got: %s", value), "IPv6", value)
+		}
+	default:
+		add(CodeUnknownFormat, fmt.Sprintf("Unknown IP version %d — expected 4 or 6", rule.Version), "", fmt.Sprint(rule.Version))
+	}
+}
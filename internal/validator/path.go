@@ -0,0 +1,86 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// checkPath validates a value of type path. Relative paths are resolved
+// against baseDir, the directory of the .env file. Existence, kind and
+// permission checks look at the filesystem of the machine running env-lint.
+func checkPath(value string, rule SchemaRule, baseDir string, add addFunc) {
+	if rule.Kind != "" && rule.Kind != "file" && rule.Kind != "dir" {
+		add(CodeUnknownFormat, fmt.Sprintf("Unknown path kind '%s' — expected file or dir", rule.Kind), "", rule.Kind)
+		return
+	}
+
+	if rule.Absolute && !filepath.IsAbs(value) {
+		add(CodePathNotAbsolute, fmt.Sprintf("Expected absolute path but got: %s", value), "absolute path", value)
+	}
+
+	path := value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if rule.MustExist || rule.Readable {
+			add(CodePathNotFound, fmt.Sprintf("Path does not exist: %s", path), "existing path", path)
+		}
+		// A path that will be created is writable when its directory is.
+		if rule.Writable && !canCreateIn(filepath.Dir(path)) {
+			add(CodePathNotWritable, fmt.Sprintf("Path cannot be created: %s", path), "writable", path)
+		}
+		return
+	}
+	if err != nil {
+		add(CodePathNotFound, fmt.Sprintf("Path cannot be accessed: %v", err), "existing path", path)
+		return
+	}
+
+	switch {
+	case rule.Kind == "file" && !info.Mode().IsRegular():
+		add(CodePathKind, fmt.Sprintf("Expected a file but got a directory or special file: %s", path), "file", path)
+	case rule.Kind == "dir" && !info.IsDir():
+		add(CodePathKind, fmt.Sprintf("Expected a directory but got: %s", path), "dir", path)
+	}
+
+	// Only regular files and directories are opened: opening a FIFO blocks
+	// until a writer appears and a socket cannot be opened at all, so other
+	// kinds of files are checked with their permissions.
+	if rule.Readable {
+		var readable bool
+		if info.Mode().IsRegular() || info.IsDir() {
+			f, err := os.Open(path)
+			if readable = err == nil; readable {
+				f.Close()
+			}
+		} else {
+			readable = canRead(path)
+		}
+		if !readable {
+			add(CodePathNotReadable, fmt.Sprintf("Path is not readable: %s", path), "readable", path)
+		}
+	}
+	if rule.Writable {
+		var writable bool
+		switch {
+		case info.IsDir():
+			writable = canCreateIn(path)
+		case info.Mode().IsRegular():
+			f, err := os.OpenFile(path, os.O_WRONLY, 0)
+			if writable = err == nil; writable {
+				f.Close()
+			}
+		default:
+			writable = canWrite(path)
+		}
+		if !writable {
+			add(CodePathNotWritable, fmt.Sprintf("Path is not writable: %s", path), "writable", path)
+		}
+	}
+}
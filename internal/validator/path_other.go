@@ -0,0 +1,25 @@
+//go:build !unix
+
+package validator
+
+import "os"
+
+// Without access(2), permissions are taken from the file mode. On Windows it
+// only reflects the read-only attribute, so every existing path counts as
+// readable and files can be created in every directory.
+
+func canRead(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func canWrite(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().Perm()&0o200 != 0
+}
+
+// canCreateIn reports whether a file can be created in dir.
+func canCreateIn(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package validator
+
+import "golang.org/x/sys/unix"
+
+// canRead, canWrite and canCreateIn ask the kernel with access(2) whether
+// the user running env-lint may use a path, without opening or creating
+// anything.
+
+func canRead(path string) bool {
+	return unix.Access(path, unix.R_OK) == nil
+}
+
+func canWrite(path string) bool {
+	return unix.Access(path, unix.W_OK) == nil
+}
+
+// canCreateIn reports whether a file can be created in dir.
+func canCreateIn(dir string) bool {
+	return unix.Access(dir, unix.W_OK|unix.X_OK) == nil
+}
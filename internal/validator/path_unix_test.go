@@ -0,0 +1,48 @@
+//go:build unix
+
+package validator
+
+import (
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestPathSpecialFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := unix.Mkfifo(filepath.Join(dir, "fifo"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("unix", filepath.Join(dir, "app.sock"))
+	if err != nil {
+		t.Skipf("cannot create a unix socket: %v", err)
+	}
+	defer l.Close()
+	opts := Options{BaseDir: dir}
+
+	tests := []struct {
+		name      string
+		rule      SchemaRule
+		value     string
+		wantCodes []string
+	}{
+		{"Readable and writable FIFO", SchemaRule{Type: "path", MustExist: true, Readable: true, Writable: true}, "fifo", nil},
+		{"Readable and writable socket", SchemaRule{Type: "path", MustExist: true, Readable: true, Writable: true}, "app.sock", nil},
+		{"Socket where file required", SchemaRule{Type: "path", Kind: "file"}, "app.sock", []string{CodePathKind}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCodes []string
+			for _, f := range checkValue(tt.value, tt.rule, opts) {
+				gotCodes = append(gotCodes, f.Code)
+			}
+			if !reflect.DeepEqual(gotCodes, tt.wantCodes) {
+				t.Errorf("Expected codes %v, got %v", tt.wantCodes, gotCodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// checkPort validates a value of type port: an integer from 1 to 65535,
+// optionally restricted to non-privileged ports (>= 1024) and to min/max.
+func checkPort(value string, rule SchemaRule, add addFunc) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		add(CodeInvalidPort, fmt.Sprintf("Expected port (1-65535) but got: %s", value), "1-65535", value)
+		return
+	}
+
+	if rule.ForbidPrivileged && port < 1024 {
+		add(CodePrivilegedPort, fmt.Sprintf("Port %d is privileged (< 1024)", port), ">= 1024", value)
+	}
+	checkRange("port", value, float64(port), rule, add, parseFloat, formatFloat)
+}
@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfilesKey is the reserved schema key that holds the profiles.
+const ProfilesKey = "$profiles"
+
+// Profile overrides the rules of some keys for one environment, e.g.
+// production. It maps keys to their overrides.
+type Profile map[string]RuleOverride
+
+// RuleOverride replaces some fields of a rule. Rule holds the new values and
+// Fields the names of the fields that were set, as written in the schema
+// ("allowed", "required", …); other fields keep the value of the base rule.
+type RuleOverride struct {
+	Rule   SchemaRule
+	Fields []string
+}
+
+// ruleFields maps the schema names of the fields of SchemaRule to their
+// index in the struct.
+var ruleFields = func() map[string]int {
+	m := make(map[string]int)
+	t := reflect.TypeOf(SchemaRule{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		m[name] = i
+	}
+	return m
+}()
+
+func (o *RuleOverride) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for name := range fields {
+		o.Fields = append(o.Fields, name)
+	}
+	sort.Strings(o.Fields)
+	if err := json.Unmarshal(data, &o.Rule); err != nil {
+		return err
+	}
+	return o.checkFields()
+}
+
+func (o *RuleOverride) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("override must be a mapping")
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		o.Fields = append(o.Fields, node.Content[i].Value)
+	}
+	sort.Strings(o.Fields)
+	if err := node.Decode(&o.Rule); err != nil {
+		return err
+	}
+	return o.checkFields()
+}
+
+// checkFields rejects unknown field names, which would otherwise be ignored
+// silently and leave the base rule in place.
+func (o RuleOverride) checkFields() error {
+	for _, name := range o.Fields {
+		if _, ok := ruleFields[name]; !ok {
+			return fmt.Errorf("unknown rule field %q", name)
+		}
+	}
+	return nil
+}
+
+// apply returns rule with the overridden fields replaced.
+func (o RuleOverride) apply(rule SchemaRule) SchemaRule {
+	dst := reflect.ValueOf(&rule).Elem()
+	src := reflect.ValueOf(o.Rule)
+	for _, name := range o.Fields {
+		i := ruleFields[name]
+		dst.Field(i).Set(src.Field(i))
+	}
+	return rule
+}
+
+// ProfileNames returns the names of the profiles of the schema, sorted.
+func (s Schema) ProfileNames() []string {
+	names := make([]string, 0, len(s.Profiles))
+	for name := range s.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithProfile returns a copy of the schema whose rules have the overrides of
+// the named profile applied.
+func (s Schema) WithProfile(name string) (Schema, error) {
+	profile, ok := s.Profiles[name]
+	if !ok {
+		if len(s.Profiles) == 0 {
+			return Schema{}, fmt.Errorf("unknown profile %q: the schema has no profiles", name)
+		}
+		return Schema{}, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(s.ProfileNames(), ", "))
+	}
+
+	rules := make(map[string]SchemaRule, len(s.Rules))
+	for key, rule := range s.Rules {
+		if o, ok := profile[key]; ok {
+			rule = o.apply(rule)
+		}
+		rules[key] = rule
+	}
+	s.Rules = rules
+	return s, nil
+}
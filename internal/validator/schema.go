@@ -0,0 +1,250 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema is a parsed schema file. Order holds the keys in the order they are
+// declared in the file, so findings can be reported in that order. Groups,
+// Constraints and Profiles hold the key groups, cross-key constraints and
+// profiles declared under the reserved "$groups", "$constraints" and
+// "$profiles" keys.
+type Schema struct {
+	Rules       map[string]SchemaRule
+	Order       []string
+	Groups      []Group
+	Constraints []Constraint
+	Profiles    map[string]Profile
+}
+
+// Keys returns every key of the schema: first the declared keys in Order,
+// then any remaining keys alphabetically.
+func (s Schema) Keys() []string {
+	keys := make([]string, 0, len(s.Rules))
+	seen := make(map[string]bool, len(s.Rules))
+	for _, key := range s.Order {
+		if _, ok := s.Rules[key]; ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	var rest []string
+	for key := range s.Rules {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(keys, rest...)
+}
+
+// ParseSchema parses a schema file in the given format ("json" or "yaml"),
+// keeping the declaration order of its keys.
+func ParseSchema(data []byte, format string) (Schema, error) {
+	switch format {
+	case "json":
+		return parseJSONSchema(data)
+	case "yaml":
+		return parseYAMLSchema(data)
+	default:
+		return Schema{}, fmt.Errorf("unsupported schema format: %s", format)
+	}
+}
+
+func parseJSONSchema(data []byte) (Schema, error) {
+	schema := Schema{Rules: make(map[string]SchemaRule)}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return Schema{}, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return Schema{}, fmt.Errorf("schema must be a JSON object")
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return Schema{}, err
+		}
+		key := tok.(string)
+
+		switch key {
+		case GroupsKey:
+			if err := dec.Decode(&schema.Groups); err != nil {
+				return Schema{}, fmt.Errorf("%s: %v", key, err)
+			}
+			continue
+		case ConstraintsKey:
+			if err := dec.Decode(&schema.Constraints); err != nil {
+				return Schema{}, fmt.Errorf("%s: %v", key, err)
+			}
+			continue
+		case ProfilesKey:
+			if err := dec.Decode(&schema.Profiles); err != nil {
+				return Schema{}, fmt.Errorf("%s: %v", key, err)
+			}
+			continue
+		}
+
+		var rule SchemaRule
+		if err := dec.Decode(&rule); err != nil {
+			return Schema{}, fmt.Errorf("%s: %v", key, err)
+		}
+		schema.add(key, rule)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return Schema{}, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return Schema{}, fmt.Errorf("unexpected data after the schema object")
+	}
+	return schema, schema.validate()
+}
+
+func parseYAMLSchema(data []byte) (Schema, error) {
+	schema := Schema{Rules: make(map[string]SchemaRule)}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return Schema{}, err
+	}
+	if len(doc.Content) == 0 {
+		return schema, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return Schema{}, fmt.Errorf("schema must be a YAML mapping")
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i].Value
+
+		switch key {
+		case GroupsKey:
+			if err := root.Content[i+1].Decode(&schema.Groups); err != nil {
+				return Schema{}, fmt.Errorf("%s: %v", key, err)
+			}
+			continue
+		case ConstraintsKey:
+			if err := root.Content[i+1].Decode(&schema.Constraints); err != nil {
+				return Schema{}, fmt.Errorf("%s: %v", key, err)
+			}
+			continue
+		case ProfilesKey:
+			if err := root.Content[i+1].Decode(&schema.Profiles); err != nil {
+				return Schema{}, fmt.Errorf("%s: %v", key, err)
+			}
+			continue
+		}
+
+		var rule SchemaRule
+		if err := root.Content[i+1].Decode(&rule); err != nil {
+			return Schema{}, fmt.Errorf("%s: %v", key, err)
+		}
+		schema.add(key, rule)
+	}
+
+	return schema, schema.validate()
+}
+
+// validate reports mistakes in the conditions, groups, constraints and
+// profiles of a schema, so they fail when the schema is loaded rather than
+// during validation.
+func (s Schema) validate() error {
+	if err := s.validateConditions(); err != nil {
+		return err
+	}
+	names := make(map[string]bool)
+	for i, g := range s.Groups {
+		if err := g.validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %v", GroupsKey, i, err)
+		}
+		for _, key := range g.allKeys() {
+			if _, ok := s.Rules[key]; !ok {
+				return fmt.Errorf("%s[%d]: key %s is not defined in the schema", GroupsKey, i, key)
+			}
+		}
+		// The findings of a group are reported under its name, which must
+		// not be mistaken for a key or another group or constraint.
+		if _, ok := s.Rules[g.DisplayName()]; ok {
+			return fmt.Errorf("%s[%d]: name %s is already a schema key", GroupsKey, i, g.DisplayName())
+		}
+		if names[g.DisplayName()] {
+			return fmt.Errorf("%s[%d]: name %s is used more than once", GroupsKey, i, g.DisplayName())
+		}
+		names[g.DisplayName()] = true
+	}
+	for i, c := range s.Constraints {
+		_, keys, err := parseExpr(c.Expr)
+		if err != nil {
+			return fmt.Errorf("%s[%d]: invalid expression %q: %v", ConstraintsKey, i, c.Expr, err)
+		}
+		for _, key := range keys {
+			if _, ok := s.Rules[key]; !ok {
+				return fmt.Errorf("%s[%d]: key %s is not defined in the schema", ConstraintsKey, i, key)
+			}
+		}
+		if _, ok := s.Rules[c.DisplayName()]; ok && c.Name != "" {
+			return fmt.Errorf("%s[%d]: name %s is already a schema key", ConstraintsKey, i, c.Name)
+		} else if ok {
+			return fmt.Errorf("%s[%d]: expression %s is a schema key; give the constraint a name", ConstraintsKey, i, c.Expr)
+		}
+		if names[c.DisplayName()] {
+			return fmt.Errorf("%s[%d]: name %s is used more than once", ConstraintsKey, i, c.DisplayName())
+		}
+		names[c.DisplayName()] = true
+	}
+	for _, name := range s.ProfileNames() {
+		for key := range s.Profiles[name] {
+			if _, ok := s.Rules[key]; !ok {
+				return fmt.Errorf("%s.%s: key %s is not defined in the schema", ProfilesKey, name, key)
+			}
+		}
+		// A profile can override requiredIf and requiredUnless.
+		p, _ := s.WithProfile(name)
+		if err := p.validateConditions(); err != nil {
+			return fmt.Errorf("%s.%s: %v", ProfilesKey, name, err)
+		}
+	}
+	return nil
+}
+
+// validateConditions reports requiredIf and requiredUnless conditions on
+// keys that are not defined in the schema.
+func (s Schema) validateConditions() error {
+	for _, key := range s.Keys() {
+		rule := s.Rules[key]
+		for _, k := range rule.RequiredIf.keys() {
+			if _, ok := s.Rules[k]; !ok {
+				return fmt.Errorf("%s.requiredIf: key %s is not defined in the schema", key, k)
+			}
+		}
+		for _, k := range rule.RequiredUnless.keys() {
+			if _, ok := s.Rules[k]; !ok {
+				return fmt.Errorf("%s.requiredUnless: key %s is not defined in the schema", key, k)
+			}
+		}
+	}
+	return nil
+}
+
+// add stores rule under key. A key declared twice keeps its first position
+// and its last rule, like a plain map decode would.
+func (s *Schema) add(key string, rule SchemaRule) {
+	if _, ok := s.Rules[key]; !ok {
+		s.Order = append(s.Order, key)
+	}
+	s.Rules[key] = rule
+}
@@ -0,0 +1,347 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSchemaKeepsOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+	}{
+		{
+			name:   "JSON",
+			format: "json",
+			data: `{
+				"PORT": {"type": "number", "required": true},
+				"APP_NAME": {"type": "string", "minLength": 3},
+				"DEBUG": {"type": "boolean", "default": false}
+			}`,
+		},
+		{
+			name:   "YAML",
+			format: "yaml",
+			data: `
+PORT:
+  type: number
+  required: true
+APP_NAME:
+  type: string
+  minLength: 3
+DEBUG:
+  type: boolean
+  default: false
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSchema([]byte(tt.data), tt.format)
+			if err != nil {
+				t.Fatalf("ParseSchema returned error: %v", err)
+			}
+
+			wantOrder := []string{"PORT", "APP_NAME", "DEBUG"}
+			if !reflect.DeepEqual(got.Order, wantOrder) {
+				t.Errorf("Expected order %v, got %v", wantOrder, got.Order)
+			}
+			if !got.Rules["PORT"].Required || got.Rules["PORT"].Type != "number" {
+				t.Errorf("Unexpected PORT rule: %+v", got.Rules["PORT"])
+			}
+			if ml := got.Rules["APP_NAME"].MinLength; ml == nil || *ml != 3 {
+				t.Errorf("Unexpected APP_NAME rule: %+v", got.Rules["APP_NAME"])
+			}
+		})
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	if _, err := ParseSchema([]byte(`["PORT"]`), "json"); err == nil {
+		t.Errorf("Expected error for non-object JSON schema")
+	}
+	if _, err := ParseSchema([]byte(`- PORT`), "yaml"); err == nil {
+		t.Errorf("Expected error for non-mapping YAML schema")
+	}
+	if _, err := ParseSchema([]byte(`{"PORT": {"type": 1}}`), "json"); err == nil {
+		t.Errorf("Expected error for invalid rule")
+	}
+	if _, err := ParseSchema([]byte(`{"PORT": {"type": "port"}} {"oops"`), "json"); err == nil {
+		t.Errorf("Expected error for data after the schema object")
+	}
+	if _, err := ParseSchema([]byte(`{}`), "toml"); err == nil {
+		t.Errorf("Expected error for unsupported format")
+	}
+	if _, err := ParseSchema([]byte(`{"TLS_CERT_PATH": {"type": "path", "requiredIf": {"TLS_ENABLD": true}}}`), "json"); err == nil {
+		t.Errorf("Expected error for condition on an undeclared key")
+	}
+	if _, err := ParseSchema([]byte(`{"$groups": [{"type": "someOf", "keys": ["A", "B"]}]}`), "json"); err == nil {
+		t.Errorf("Expected error for unknown group type")
+	}
+	if _, err := ParseSchema([]byte(`{"DATABASE_URL": {"type": "url"}, "$groups": [{"type": "oneOf", "keys": ["DATABASE_URL", "DB_HOST"], "name": "DATABASE_URL"}]}`), "json"); err == nil {
+		t.Errorf("Expected error for group named like a schema key")
+	}
+	if _, err := ParseSchema([]byte(`{"REDIS_URL": {"type": "url"}, "$groups": [{"type": "exclusive", "keys": ["REDIS_URL", "REDIS_SENTINAL"]}]}`), "json"); err == nil {
+		t.Errorf("Expected error for group with an undeclared key")
+	}
+	if _, err := ParseSchema([]byte(`{"A": {"type": "string"}, "B": {"type": "string"}, "$groups": [{"type": "anyOf", "keys": ["A", "B"], "name": "ab"}], "$constraints": [{"expr": "A != B", "name": "ab"}]}`), "json"); err == nil {
+		t.Errorf("Expected error for group and constraint with the same name")
+	}
+	if _, err := ParseSchema([]byte(`{"A": {"type": "string"}, "B": {"type": "string"}, "$constraints": [{"expr": "A != B"}, {"expr": "A != B"}]}`), "json"); err == nil {
+		t.Errorf("Expected error for duplicate constraints")
+	}
+	if _, err := ParseSchema([]byte("$groups:\n  - type: oneOf\n    keys: [A]\n"), "yaml"); err == nil {
+		t.Errorf("Expected error for group with a single key")
+	}
+	if _, err := ParseSchema([]byte(`{"DEBUG": {"type": "boolean"}, "$profiles": {"prod": {"DEBUG": {"alowed": [false]}}}}`), "json"); err == nil {
+		t.Errorf("Expected error for unknown field in profile override")
+	}
+	if _, err := ParseSchema([]byte("$profiles:\n  prod:\n    DEBUG:\n      required: true\n"), "yaml"); err == nil {
+		t.Errorf("Expected error for profile override of an undeclared key")
+	}
+	if _, err := ParseSchema([]byte(`{"DEBUG": {"type": "boolean"}, "$profiles": {"prod": {"DEBUG": {"requiredIf": {"ENV": "prod"}}}}}`), "json"); err == nil {
+		t.Errorf("Expected error for profile condition on an undeclared key")
+	}
+	if _, err := ParseSchema([]byte(`{"POOL_MIN": {"type": "integer"}, "POOL_MAX": {"type": "integer"}, "$constraints": [{"expr": "POOL_MIN <= POOL_MAXX"}]}`), "json"); err == nil {
+		t.Errorf("Expected error for constraint on an undeclared key")
+	}
+	if _, err := ParseSchema([]byte(`{"DEBUG": {"type": "boolean"}, "$constraints": [{"expr": "!DEBUG", "name": "DEBUG"}]}`), "json"); err == nil {
+		t.Errorf("Expected error for constraint named like a schema key")
+	}
+	for _, expr := range []string{"A <=", "A = B", "(A < B", "A < B C", `A == "x`, "if A", "A if"} {
+		data := fmt.Sprintf(`{"$constraints": [{"expr": %q}]}`, expr)
+		if _, err := ParseSchema([]byte(data), "json"); err == nil {
+			t.Errorf("Expected error for constraint %q", expr)
+		}
+	}
+}
+
+func TestParseSchemaConstraints(t *testing.T) {
+	got, err := ParseSchema([]byte(`
+POOL_MIN:
+  type: integer
+POOL_MAX:
+  type: integer
+ADMIN_EMAILS:
+  type: list
+ENV:
+  type: string
+$constraints:
+  - expr: POOL_MIN <= POOL_MAX
+    message: POOL_MIN must not exceed POOL_MAX
+  - expr: len(ADMIN_EMAILS) > 0 if ENV == "production"
+`), "yaml")
+	if err != nil {
+		t.Fatalf("ParseSchema returned error: %v", err)
+	}
+	want := []Constraint{
+		{Expr: "POOL_MIN <= POOL_MAX", Message: "POOL_MIN must not exceed POOL_MAX"},
+		{Expr: `len(ADMIN_EMAILS) > 0 if ENV == "production"`},
+	}
+	if !reflect.DeepEqual(got.Constraints, want) || !reflect.DeepEqual(got.Keys(), []string{"POOL_MIN", "POOL_MAX", "ADMIN_EMAILS", "ENV"}) {
+		t.Errorf("Unexpected schema: %+v", got)
+	}
+}
+
+func TestParseSchemaProfiles(t *testing.T) {
+	tests := []struct {
+		format string
+		data   string
+	}{
+		{"json", `{
+  "DEBUG": {"type": "boolean", "default": true},
+  "LOG_LEVEL": {"type": "string", "allowed": ["debug", "info", "warn"], "minLength": 4},
+  "$profiles": {
+    "production": {
+      "DEBUG": {"allowed": [false], "required": true},
+      "LOG_LEVEL": {"disallowed": ["debug"], "minLength": 5}
+    },
+    "development": {}
+  }
+}`},
+		{"yaml", `DEBUG:
+  type: boolean
+  default: true
+LOG_LEVEL:
+  type: string
+  allowed: [debug, info, warn]
+  minLength: 4
+$profiles:
+  production:
+    DEBUG:
+      allowed: [false]
+      required: true
+    LOG_LEVEL:
+      disallowed: [debug]
+      minLength: 5
+  development: {}
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			base, err := ParseSchema([]byte(tt.data), tt.format)
+			if err != nil {
+				t.Fatalf("ParseSchema returned error: %v", err)
+			}
+			if !reflect.DeepEqual(base.ProfileNames(), []string{"development", "production"}) {
+				t.Errorf("Unexpected profiles: %v", base.ProfileNames())
+			}
+
+			prod, err := base.WithProfile("production")
+			if err != nil {
+				t.Fatalf("WithProfile returned error: %v", err)
+			}
+			debug := prod.Rules["DEBUG"]
+			if debug.Type != "boolean" || !debug.Required || fmt.Sprint(debug.Allowed) != "[false]" || debug.Default != true {
+				t.Errorf("Unexpected production DEBUG rule: %+v", debug)
+			}
+			logLevel := prod.Rules["LOG_LEVEL"]
+			if len(logLevel.Allowed) != 3 || fmt.Sprint(logLevel.Disallowed) != "[debug]" || *logLevel.MinLength != 5 {
+				t.Errorf("Unexpected production LOG_LEVEL rule: %+v", logLevel)
+			}
+			if base.Rules["DEBUG"].Required || *base.Rules["LOG_LEVEL"].MinLength != 4 {
+				t.Errorf("WithProfile changed the base schema: %+v", base.Rules)
+			}
+
+			got := Validate(map[string]string{"LOG_LEVEL": "debug"}, prod, Options{})
+			if len(got.FindingsFor("DEBUG", SeverityError)) != 1 || len(got.FindingsFor("LOG_LEVEL", SeverityError)) != 1 {
+				t.Errorf("Expected DEBUG and LOG_LEVEL to fail in production, got %v", got.Errors)
+			}
+
+			if _, err := base.WithProfile("prod"); err == nil || !strings.Contains(err.Error(), "development, production") {
+				t.Errorf("Expected unknown profile error listing the profiles, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseSchemaGroups(t *testing.T) {
+	tests := []struct {
+		format string
+		data   string
+	}{
+		{"json", `{
+  "DATABASE_URL": {"type": "url"},
+  "DB_HOST": {"type": "hostname"},
+  "DB_USER": {"type": "string"},
+  "DB_NAME": {"type": "string"},
+  "AWS_ACCESS_KEY_ID": {"type": "string"},
+  "AWS_SECRET_ACCESS_KEY": {"type": "string"},
+  "$groups": [
+    {"type": "oneOf", "name": "database", "keys": ["DATABASE_URL", ["DB_HOST", "DB_USER", "DB_NAME"]]},
+    {"type": "allOrNone", "keys": ["AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"]}
+  ]
+}`},
+		{"yaml", `DATABASE_URL:
+  type: url
+DB_HOST: {type: hostname}
+DB_USER: {type: string}
+DB_NAME: {type: string}
+AWS_ACCESS_KEY_ID: {type: string}
+AWS_SECRET_ACCESS_KEY: {type: string}
+$groups:
+  - type: oneOf
+    name: database
+    keys:
+      - DATABASE_URL
+      - [DB_HOST, DB_USER, DB_NAME]
+  - type: allOrNone
+    keys: [AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY]
+`},
+	}
+
+	want := []Group{
+		{Type: "oneOf", Name: "database", Keys: []GroupMember{{"DATABASE_URL"}, {"DB_HOST", "DB_USER", "DB_NAME"}}},
+		{Type: "allOrNone", Keys: []GroupMember{{"AWS_ACCESS_KEY_ID"}, {"AWS_SECRET_ACCESS_KEY"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := ParseSchema([]byte(tt.data), tt.format)
+			if err != nil {
+				t.Fatalf("ParseSchema returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got.Keys(), []string{"DATABASE_URL", "DB_HOST", "DB_USER", "DB_NAME", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"}) {
+				t.Errorf("Expected $groups not to be a key, got %v", got.Keys())
+			}
+			if !reflect.DeepEqual(got.Groups, want) {
+				t.Errorf("Expected groups %+v, got %+v", want, got.Groups)
+			}
+		})
+	}
+}
+
+func TestValidateIsDeterministic(t *testing.T) {
+	env := map[string]string{
+		"ZETA":  "x",
+		"ALPHA": "y",
+		"C":     "not-a-number",
+		"B":     "not-a-bool",
+	}
+	schema := Schema{
+		Rules: map[string]SchemaRule{
+			"C": {Type: "number"},
+			"B": {Type: "boolean"},
+			"A": {Type: "string", Required: true},
+			"D": {Type: "string"},
+		},
+		Order: []string{"C", "B"},
+	}
+
+	want := []string{"C", "B", "A", "D", "ALPHA", "ZETA"}
+	for i := 0; i < 20; i++ {
+		got := Validate(env, schema, Options{StrictMode: true})
+		var keys []string
+		for _, f := range got.Findings {
+			keys = append(keys, f.Key)
+		}
+		if !reflect.DeepEqual(keys, want) {
+			t.Fatalf("Expected findings in order %v, got %v", want, keys)
+		}
+	}
+}
+
+func TestParseSchemaBounds(t *testing.T) {
+	tests := []struct {
+		format string
+		data   string
+	}{
+		{"json", `{"TIMEOUT": {"type": "duration", "min": "5s", "max": "1m"}, "PORT": {"type": "number", "min": 1000, "max": 9999.5},
+			"RETRIES": {"type": "integer", "min": null, "max": 5}, "$profiles": {"dev": {"RETRIES": {"max": null}}}}`},
+		{"yaml", "TIMEOUT:\n  type: duration\n  min: 5s\n  max: 1m\nPORT:\n  type: number\n  min: 1000\n  max: 9999.5\n" +
+			"RETRIES:\n  type: integer\n  min: ~\n  max: 5\n$profiles:\n  dev:\n    RETRIES:\n      max: null\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := ParseSchema([]byte(tt.data), tt.format)
+			if err != nil {
+				t.Fatalf("ParseSchema returned error: %v", err)
+			}
+			if r := got.Rules["TIMEOUT"]; r.Min != "5s" || r.Max != "1m" {
+				t.Errorf("Unexpected TIMEOUT bounds: %q, %q", r.Min, r.Max)
+			}
+			if r := got.Rules["PORT"]; r.Min != "1000" || r.Max != "9999.5" {
+				t.Errorf("Unexpected PORT bounds: %q, %q", r.Min, r.Max)
+			}
+			if r := got.Rules["RETRIES"]; r.Min != "" || r.Max != "5" {
+				t.Errorf("Unexpected RETRIES bounds: %q, %q", r.Min, r.Max)
+			}
+			dev, err := got.WithProfile("dev")
+			if err != nil {
+				t.Fatalf("WithProfile returned error: %v", err)
+			}
+			if r := dev.Rules["RETRIES"]; r.Max != "" {
+				t.Errorf("Expected the dev profile to remove the RETRIES max, got %q", r.Max)
+			}
+		})
+	}
+
+	if _, err := ParseSchema([]byte(`{"PORT": {"type": "number", "min": true}}`), "json"); err == nil {
+		t.Errorf("Expected error for boolean bound")
+	}
+}
@@ -0,0 +1,346 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// semver is a parsed semantic version (https://semver.org). Build metadata
+// is dropped because it does not affect precedence.
+type semver struct {
+	major, minor, patch uint64
+	pre                 []string
+}
+
+// parseSemver parses a full semantic version with an optional "v" prefix,
+// e.g. 1.4.2 or v2.0.0-rc.1+build.5.
+func parseSemver(s string) (semver, error) {
+	v, n, err := parsePartialSemver(s)
+	if err != nil {
+		return semver{}, err
+	}
+	if n != 3 {
+		return semver{}, fmt.Errorf("version %q must have major, minor and patch", s)
+	}
+	return v, nil
+}
+
+// parsePartialSemver parses a version of which minor and patch may be
+// missing or wildcards (x, X or *), as used in constraints. It returns the
+// number of parts that were given.
+func parsePartialSemver(s string) (semver, int, error) {
+	var v semver
+	rest := strings.TrimPrefix(s, "v")
+
+	if i := strings.IndexByte(rest, '+'); i >= 0 {
+		if !validIdentifiers(rest[i+1:], false) {
+			return v, 0, fmt.Errorf("invalid build metadata in %q", s)
+		}
+		rest = rest[:i]
+	}
+	if i := strings.IndexByte(rest, '-'); i >= 0 {
+		if !validIdentifiers(rest[i+1:], true) {
+			return v, 0, fmt.Errorf("invalid pre-release in %q", s)
+		}
+		v.pre = strings.Split(rest[i+1:], ".")
+		rest = rest[:i]
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) > 3 {
+		return v, 0, fmt.Errorf("invalid version %q", s)
+	}
+	nums := []*uint64{&v.major, &v.minor, &v.patch}
+	n := 0
+	for i, p := range parts {
+		wildcard := p == "x" || p == "X" || p == "*"
+		switch {
+		case wildcard:
+		case n < i:
+			// A number after a wildcard, e.g. 1.x.3.
+			return v, 0, fmt.Errorf("invalid version %q", s)
+		default:
+			num, err := parseNumericIdentifier(p)
+			if err != nil {
+				return v, 0, fmt.Errorf("invalid version %q", s)
+			}
+			*nums[n] = num
+			n++
+		}
+	}
+	if v.pre != nil && n != 3 {
+		return v, 0, fmt.Errorf("pre-release needs a full version in %q", s)
+	}
+	return v, n, nil
+}
+
+func parseNumericIdentifier(s string) (uint64, error) {
+	if s == "" || len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("invalid number %q", s)
+		}
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// validIdentifiers checks dot-separated pre-release or build identifiers.
+// Numeric pre-release identifiers must not have leading zeros.
+func validIdentifiers(s string, pre bool) bool {
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return false
+		}
+		numeric := true
+		for _, r := range id {
+			switch {
+			case r >= '0' && r <= '9':
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-':
+				numeric = false
+			default:
+				return false
+			}
+		}
+		if pre && numeric && len(id) > 1 && id[0] == '0' {
+			return false
+		}
+	}
+	return true
+}
+
+// compare returns -1, 0 or 1 following SemVer precedence: a version with a
+// pre-release is lower than the same version without one.
+func (v semver) compare(o semver) int {
+	for _, d := range [][2]uint64{{v.major, o.major}, {v.minor, o.minor}, {v.patch, o.patch}} {
+		if d[0] != d[1] {
+			if d[0] < d[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case len(v.pre) == 0 && len(o.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(o.pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.pre) && i < len(o.pre); i++ {
+		if c := compareIdentifier(v.pre[i], o.pre[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(v.pre) < len(o.pre):
+		return -1
+	case len(v.pre) > len(o.pre):
+		return 1
+	}
+	return 0
+}
+
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		if an == bn {
+			return 0
+		}
+		if an < bn {
+			return -1
+		}
+		return 1
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func (v semver) sameCore(o semver) bool {
+	return v.major == o.major && v.minor == o.minor && v.patch == o.patch
+}
+
+// comparator is a single primitive condition of a constraint, e.g. >=1.2.0.
+type comparator struct {
+	op string
+	v  semver
+}
+
+func (c comparator) matches(v semver) bool {
+	cmp := v.compare(c.v)
+	switch c.op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "!=":
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
+
+// constraint is a version constraint in disjunctive form: a version
+// satisfies it when it satisfies every comparator of at least one set.
+type constraint [][]comparator
+
+// parseConstraint parses constraints such as ">=1.2 <2.0", "^1.4" or
+// "~1.2.3 || >=2.1". Conditions separated by spaces or commas must all
+// hold; "||" separates alternatives. Partial versions act as ranges:
+// "1.2" is >=1.2.0 <1.3.0, "<=1.2" is <1.3.0 and ">1.2" is >=1.3.0.
+func parseConstraint(s string) (constraint, error) {
+	var c constraint
+	for _, alt := range strings.Split(s, "||") {
+		tokens := strings.FieldsFunc(alt, func(r rune) bool { return r == ' ' || r == ',' || r == '\t' })
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("empty constraint in %q", s)
+		}
+
+		var set []comparator
+		for i := 0; i < len(tokens); i++ {
+			tok := tokens[i]
+			// Allow a space between operator and version: ">= 1.2".
+			if strings.Trim(tok, "<>=!~^") == "" && i+1 < len(tokens) {
+				i++
+				tok += tokens[i]
+			}
+			comps, err := parseComparator(tok)
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, comps...)
+		}
+		c = append(c, set)
+	}
+	return c, nil
+}
+
+func parseComparator(tok string) ([]comparator, error) {
+	op := ""
+	for _, candidate := range []string{">=", "<=", "!=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(tok, candidate) {
+			op = candidate
+			break
+		}
+	}
+	v, n, err := parsePartialSemver(tok[len(op):])
+	if err != nil {
+		return nil, err
+	}
+
+	// next is the first version after the range the partial version covers.
+	next := v
+	next.pre = nil
+	switch n {
+	case 0:
+		return nil, nil
+	case 1:
+		next = semver{major: v.major + 1}
+	case 2:
+		next = semver{major: v.major, minor: v.minor + 1}
+	}
+
+	switch op {
+	case "", "=":
+		if n == 3 {
+			return []comparator{{"=", v}}, nil
+		}
+		return []comparator{{">=", v}, {"<", next}}, nil
+	case "!=":
+		if n != 3 {
+			return nil, fmt.Errorf("!= needs a full version in %q", tok)
+		}
+		return []comparator{{"!=", v}}, nil
+	case ">":
+		if n == 3 {
+			return []comparator{{">", v}}, nil
+		}
+		return []comparator{{">=", next}}, nil
+	case ">=", "<":
+		return []comparator{{op, v}}, nil
+	case "<=":
+		if n == 3 {
+			return []comparator{{"<=", v}}, nil
+		}
+		return []comparator{{"<", next}}, nil
+	case "~":
+		upper := semver{major: v.major, minor: v.minor + 1}
+		if n == 1 {
+			upper = semver{major: v.major + 1}
+		}
+		return []comparator{{">=", v}, {"<", upper}}, nil
+	default: // "^"
+		upper := semver{major: v.major + 1}
+		switch {
+		case v.major > 0 || n == 1:
+		case v.minor > 0 || n == 2:
+			upper = semver{minor: v.minor + 1}
+		default:
+			upper = semver{patch: v.patch + 1}
+		}
+		return []comparator{{">=", v}, {"<", upper}}, nil
+	}
+}
+
+// satisfiedBy reports whether v satisfies the constraint. As with npm and
+// Cargo, a pre-release only satisfies a set that mentions a pre-release of
+// the same major.minor.patch, so ">=1.2 <2.0" does not allow 2.0.0-rc.1.
+func (c constraint) satisfiedBy(v semver) bool {
+	for _, set := range c {
+		ok := true
+		for _, comp := range set {
+			if !comp.matches(v) {
+				ok = false
+				break
+			}
+		}
+		if ok && len(v.pre) > 0 {
+			ok = false
+			for _, comp := range set {
+				if len(comp.v.pre) > 0 && comp.v.sameCore(v) {
+					ok = true
+					break
+				}
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSemver validates a value of type semver and, when the rule has one,
+// its constraint.
+func checkSemver(value string, rule SchemaRule, add addFunc) {
+	v, err := parseSemver(value)
+	if err != nil {
+		add(CodeInvalidSemver, fmt.Sprintf("Expected semantic version (e.g. 1.4.2) but got: %s", value), "semver", value)
+		return
+	}
+	if rule.Constraint == "" {
+		return
+	}
+
+	c, err := parseConstraint(rule.Constraint)
+	if err != nil {
+		add(CodeInvalidConstraint, fmt.Sprintf("Invalid version constraint '%s': %v", rule.Constraint, err), "", rule.Constraint)
+		return
+	}
+	if !c.satisfiedBy(v) {
+		add(CodeSemverConstraint, fmt.Sprintf("Version %s does not satisfy constraint: %s", value, rule.Constraint), rule.Constraint, value)
+	}
+}
@@ -0,0 +1,64 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// checkURL validates a value of type url. The URL must be absolute; the
+// schemes, requireHost, forbidCredentials and requirePath rules narrow it
+// down further. Values are reported with their password redacted.
+func checkURL(value string, rule SchemaRule, add addFunc) {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" {
+		add(CodeInvalidURL, "Expected absolute URL (e.g. https://example.com)", "url", redactURL(value, u))
+		return
+	}
+	shown := redactURL(value, u)
+
+	if len(rule.Schemes) > 0 {
+		allowed := false
+		for _, scheme := range rule.Schemes {
+			if strings.EqualFold(u.Scheme, scheme) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			add(CodeURLScheme,
+				fmt.Sprintf("URL scheme '%s' is not allowed. Expected one of: %v", u.Scheme, rule.Schemes),
+				fmt.Sprintf("one of %v", rule.Schemes), u.Scheme)
+		}
+	}
+	if rule.RequireHost && u.Host == "" {
+		add(CodeURLHost, "URL must include a host", "host", shown)
+	}
+	if rule.ForbidCredentials && u.User != nil {
+		add(CodeURLCredentials, "URL must not include credentials", "no userinfo", shown)
+	}
+	if rule.RequirePath && strings.Trim(u.Path, "/") == "" {
+		add(CodeURLPath, "URL must include a path", "path", shown)
+	}
+}
+
+// redactURL returns value with its password hidden. A value that could not
+// be parsed, or only as an opaque URL such as "admin:pw@db", loses everything
+// between the scheme and the last "@" instead, since that is where a DSN
+// keeps its credentials.
+func redactURL(value string, u *url.URL) string {
+	if u != nil && u.Opaque == "" {
+		return u.Redacted()
+	}
+	at := strings.LastIndex(value, "@")
+	if at < 0 {
+		return value
+	}
+	start := strings.Index(value, "://")
+	if start < 0 || start > at {
+		start = 0
+	} else {
+		start += len("://")
+	}
+	return value[:start] + "xxxxx" + value[at:]
+}
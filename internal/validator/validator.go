@@ -3,8 +3,11 @@ package validator
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/chidinma21/env-lint/internal/envfile"
 )
 
 type SchemaRule struct {
@@ -12,47 +15,266 @@ type SchemaRule struct {
 	Required    bool          `json:"required,omitempty" yaml:"required,omitempty"`
 	Default     interface{}   `json:"default,omitempty" yaml:"default,omitempty"`
 	Allowed     []interface{} `json:"allowed,omitempty" yaml:"allowed,omitempty"`
+	Disallowed  []interface{} `json:"disallowed,omitempty" yaml:"disallowed,omitempty"`
 	Pattern     string        `json:"pattern,omitempty" yaml:"pattern,omitempty"`
 	Length      *int          `json:"length,omitempty" yaml:"length,omitempty"`
 	MaxLength   *int          `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
 	MinLength   *int          `json:"minLength,omitempty" yaml:"minLength,omitempty"`
-	Min         *float64      `json:"min,omitempty" yaml:"min,omitempty"`
-	Max         *float64      `json:"max,omitempty" yaml:"max,omitempty"`
+	Min         Bound         `json:"min,omitempty" yaml:"min,omitempty"`
+	Max         Bound         `json:"max,omitempty" yaml:"max,omitempty"`
 	CustomError string        `json:"customError,omitempty" yaml:"customError,omitempty"`
+
+	// RequiredIf makes the key required when the condition holds, and
+	// RequiredUnless when it does not.
+	RequiredIf     Condition `json:"requiredIf,omitempty" yaml:"requiredIf,omitempty"`
+	RequiredUnless Condition `json:"requiredUnless,omitempty" yaml:"requiredUnless,omitempty"`
+
+	// Format refines a type: the bit size and signedness of an integer
+	// ("int32", "uint16", …), the alphabet of base64 ("std", "rawurl", …) or
+	// the layout of a date or datetime ("2006-01-02", "RFC3339", …).
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+
+	// url
+	Schemes           []string `json:"schemes,omitempty" yaml:"schemes,omitempty"`
+	RequireHost       bool     `json:"requireHost,omitempty" yaml:"requireHost,omitempty"`
+	ForbidCredentials bool     `json:"forbidCredentials,omitempty" yaml:"forbidCredentials,omitempty"`
+	RequirePath       bool     `json:"requirePath,omitempty" yaml:"requirePath,omitempty"`
+
+	// port
+	ForbidPrivileged bool `json:"forbidPrivileged,omitempty" yaml:"forbidPrivileged,omitempty"`
+
+	// list
+	Separator string      `json:"separator,omitempty" yaml:"separator,omitempty"`
+	Items     *SchemaRule `json:"items,omitempty" yaml:"items,omitempty"`
+	MinItems  *int        `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems  *int        `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+	Unique    bool        `json:"unique,omitempty" yaml:"unique,omitempty"`
+
+	// path
+	MustExist bool   `json:"mustExist,omitempty" yaml:"mustExist,omitempty"`
+	Kind      string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Readable  bool   `json:"readable,omitempty" yaml:"readable,omitempty"`
+	Writable  bool   `json:"writable,omitempty" yaml:"writable,omitempty"`
+	Absolute  bool   `json:"absolute,omitempty" yaml:"absolute,omitempty"`
+
+	// semver
+	Constraint string `json:"constraint,omitempty" yaml:"constraint,omitempty"`
+
+	// cron
+	MinInterval string `json:"minInterval,omitempty" yaml:"minInterval,omitempty"`
+
+	// JSONSchema is a JSON Schema fragment that a value of type json must
+	// match (see checkJSONSchema for the supported keywords).
+	JSONSchema map[string]interface{} `json:"schema,omitempty" yaml:"schema,omitempty"`
+
+	// Version restricts ip and cidr values to IPv4 (4) or IPv6 (6), and
+	// uuid values to a UUID version (1-8).
+	Version int `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// Severity is how serious a finding is. Only errors fail validation.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a single problem found while validating a key. Code is the
+// stable code of the check that failed and Rule the schema rule behind it
+// (e.g. "pattern" or "minLength"); Expected and Actual describe the
+// constraint and the offending value where that makes sense.
+type Finding struct {
+	Key      string            `json:"key"`
+	Code     string            `json:"code"`
+	Rule     string            `json:"rule"`
+	Severity Severity          `json:"severity"`
+	Message  string            `json:"message"`
+	Expected string            `json:"expected,omitempty"`
+	Actual   string            `json:"actual,omitempty"`
+	Position *envfile.Position `json:"position,omitempty"`
+
+	// Keys lists the keys of a group or constraint finding, whose Key is the
+	// name of the group or constraint rather than a key.
+	Keys []string `json:"keys,omitempty"`
 }
 
 type ValidationResult struct {
-	Passed    bool
+	Passed bool
+
+	// Findings lists every error and warning, including one error per extra
+	// key in strict mode. A key can have several findings.
+	Findings []Finding
+
+	// Errors and Warnings hold the first error and warning message of each
+	// schema key, for callers that only need one message per key. Findings
+	// of extra keys, key groups and constraints are only in Findings.
 	Errors    map[string]string
 	Warnings  map[string]string
 	ExtraKeys []string
+
+	// Positions holds the position in the .env file of every key that has an
+	// error, a warning or is an extra key. Keys missing from the file have no
+	// position.
+	Positions map[string]envfile.Position
+}
+
+// FindingsFor returns the findings of key with the given severity.
+func (r ValidationResult) FindingsFor(key string, severity Severity) []Finding {
+	var out []Finding
+	for _, f := range r.Findings {
+		if f.Key == key && f.Severity == severity {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// WithoutWarnings returns a copy of r without warnings, for reports that
+// should only show errors. Positions of keys that only had warnings are
+// dropped as well.
+func (r ValidationResult) WithoutWarnings() ValidationResult {
+	out := r
+	out.Findings = nil
+	out.Warnings = make(map[string]string)
+	out.Positions = make(map[string]envfile.Position)
+	for _, f := range r.Findings {
+		if f.Severity == SeverityWarning {
+			continue
+		}
+		out.Findings = append(out.Findings, f)
+		if pos, ok := r.Positions[f.Key]; ok {
+			out.Positions[f.Key] = pos
+		}
+	}
+	return out
+}
+
+// Options controls how Validate checks an environment.
+type Options struct {
+	FailFast   bool
+	StrictMode bool
+
+	// Positions maps keys to where they are defined in the .env file.
+	Positions map[string]envfile.Position
+
+	// BaseDir is the directory relative values of type path are resolved
+	// against, normally the directory of the .env file. Empty means the
+	// working directory.
+	BaseDir string
 }
 
 func ValidateEnv(envMap map[string]string, schema map[string]SchemaRule, failFast, strictMode bool) ValidationResult {
-	errors := make(map[string]string)
-	warnings := make(map[string]string)
-	extraKeys := []string{}
-	passed := true
+	return Validate(envMap, Schema{Rules: schema}, Options{FailFast: failFast, StrictMode: strictMode})
+}
+
+// validation accumulates the findings of a single Validate call.
+type validation struct {
+	opts   Options
+	rules  map[string]SchemaRule
+	result ValidationResult
+	// stopped is set once fail-fast mode has recorded its first error.
+	stopped bool
+}
+
+// firstPosition returns the position of the first of keys that is defined
+// in the .env file, for findings that concern several keys.
+func (v *validation) firstPosition(keys []string) *envfile.Position {
+	for _, key := range keys {
+		if pos, ok := v.opts.Positions[key]; ok {
+			return &pos
+		}
+	}
+	return nil
+}
+
+func (v *validation) add(key string, f Finding) {
+	if v.stopped {
+		return
+	}
+	f.Key = key
+	if pos, ok := v.opts.Positions[f.Key]; ok {
+		f.Position = &pos
+		v.result.Positions[f.Key] = pos
+	}
+	v.result.Findings = append(v.result.Findings, f)
+	if f.Severity == SeverityError {
+		v.result.Passed = false
+		v.stopped = v.opts.FailFast
+	}
+
+	if _, ok := v.rules[f.Key]; !ok {
+		return
+	}
+	switch f.Severity {
+	case SeverityError:
+		if _, ok := v.result.Errors[f.Key]; !ok {
+			v.result.Errors[f.Key] = f.Message
+		}
+	case SeverityWarning:
+		if _, ok := v.result.Warnings[f.Key]; !ok {
+			v.result.Warnings[f.Key] = f.Message
+		}
+	}
+}
+
+// Validate checks envMap against schema. Keys are checked in schema order
+// (see Schema.Keys), then key groups and constraints in declaration order,
+// and extra keys are reported alphabetically, so findings are always in the
+// same order. Missing optional keys that have a default are
+// added to envMap.
+func Validate(envMap map[string]string, schema Schema, opts Options) ValidationResult {
+	v := &validation{
+		opts:  opts,
+		rules: schema.Rules,
+		result: ValidationResult{
+			Passed:    true,
+			Errors:    make(map[string]string),
+			Warnings:  make(map[string]string),
+			ExtraKeys: []string{},
+			Positions: make(map[string]envfile.Position),
+		},
+	}
 
-	fail := func(key, msg string) ValidationResult {
-		errors[key] = msg
-		return ValidationResult{
-			Passed:   false,
-			Errors:   errors,
-			Warnings: warnings,
+	// Extra keys and the keys that count as present for groups are
+	// collected before defaults are added to envMap.
+	var extraKeys []string
+	present := make(map[string]bool, len(envMap))
+	for key, value := range envMap {
+		if _, exists := schema.Rules[key]; !exists {
+			extraKeys = append(extraKeys, key)
+		}
+		present[key] = value != ""
+	}
+	sort.Strings(extraKeys)
+
+	// Conditions and constraints see every value, including the defaults of
+	// keys that are checked later.
+	known := make(map[string]string, len(envMap))
+	for key, value := range envMap {
+		known[key] = value
+	}
+	for key, rule := range schema.Rules {
+		if _, ok := known[key]; !ok && rule.Default != nil {
+			known[key] = fmt.Sprintf("%v", rule.Default)
 		}
 	}
 
-	for key, rule := range schema {
+	for _, key := range schema.Keys() {
+		if v.stopped {
+			break
+		}
+		rule := schema.Rules[key]
 		value, ok := envMap[key]
 
 		// Missing required key
 		if !ok {
 			if rule.Required {
-				if failFast {
-					return fail(key, "Missing required key")
-				}
-				errors[key] = "Missing required key"
+				v.add(key, newFinding(CodeMissingRequired, "Missing required key", "", ""))
+				continue
+			}
+			if f, required := conditionallyRequired(rule, known, schema.Rules); required {
+				v.add(key, f)
 				continue
 			}
 			// Optional key handling
@@ -60,153 +282,201 @@ func ValidateEnv(envMap map[string]string, schema map[string]SchemaRule, failFas
 				defaultStr := fmt.Sprintf("%v", rule.Default)
 				envMap[key] = defaultStr
 				value = defaultStr
-				warnings[key] = "Missing optional key — using default"
+				v.add(key, newFinding(CodeDefaultUsed, "Missing optional key — using default", "", defaultStr))
 			} else {
-				warnings[key] = "Missing optional key (ok)"
+				v.add(key, newFinding(CodeMissingOptional, "Missing optional key (ok)", "", ""))
 				continue
 			}
 		}
 
-		// Allowed values check
-		if len(rule.Allowed) > 0 {
-			valueStr := fmt.Sprintf("%v", value)
-			valid := false
-			for _, allowed := range rule.Allowed {
-				if valueStr == fmt.Sprintf("%v", allowed) {
-					valid = true
-					break
-				}
-			}
-			if !valid {
-				msg := fmt.Sprintf("Value '%s' is not allowed. Expected one of: %v", value, rule.Allowed)
-				if rule.CustomError != "" {
-					msg = rule.CustomError
-				}
-				if failFast {
-					return fail(key, msg)
-				}
-				errors[key] = msg
+		for _, f := range checkValue(value, rule, opts) {
+			if f.Severity == SeverityError && rule.CustomError != "" {
+				f.Message = rule.CustomError
 			}
+			v.add(key, f)
 		}
+	}
 
-		// Type checks
-		switch rule.Type {
-		case "string":
-			if rule.Pattern != "" {
-				if matched, err := regexp.MatchString(rule.Pattern, value); err != nil {
-					warnings[key] = fmt.Sprintf("Invalid regex pattern: %s", rule.Pattern)
-				} else if !matched {
-					msg := fmt.Sprintf("Value does not match pattern: %s", rule.Pattern)
-					if rule.CustomError != "" {
-						msg = rule.CustomError
-					}
-					if failFast {
-						return fail(key, msg)
-					}
-					errors[key] = msg
-				}
-			}
-			if rule.Length != nil && len(value) != *rule.Length {
-				msg := fmt.Sprintf("Expected string of length [%v] but got: %s", *rule.Length, value)
-				if rule.CustomError != "" {
-					msg = rule.CustomError
-				}
-				if failFast {
-					return fail(key, msg)
-				}
-				errors[key] = msg
-			}
-			if rule.MaxLength != nil && len(value) > *rule.MaxLength {
-				msg := fmt.Sprintf("Expected max length [%v] but got: %s", *rule.MaxLength, value)
-				if rule.CustomError != "" {
-					msg = rule.CustomError
-				}
-				if failFast {
-					return fail(key, msg)
-				}
-				errors[key] = msg
-			}
-			if rule.MinLength != nil && len(value) < *rule.MinLength {
-				msg := fmt.Sprintf("Expected min length [%v] but got: %s", *rule.MinLength, value)
-				if rule.CustomError != "" {
-					msg = rule.CustomError
-				}
-				if failFast {
-					return fail(key, msg)
-				}
-				errors[key] = msg
-			}
+	for _, g := range schema.Groups {
+		if v.stopped {
+			break
+		}
+		if f, failed := checkGroup(g, present); failed {
+			f.Position = v.firstPosition(f.Keys)
+			v.add(g.DisplayName(), f)
+		}
+	}
 
-		case "number":
-			num, err := strconv.ParseFloat(value, 64)
-			if err != nil {
-				msg := fmt.Sprintf("Expected number but got: %s", value)
-				if rule.CustomError != "" {
-					msg = rule.CustomError
-				}
-				if failFast {
-					return fail(key, msg)
-				}
-				errors[key] = msg
-				continue
-			}
-			if rule.Min != nil && num < *rule.Min {
-				msg := fmt.Sprintf("Expected number >= %.2f but got: %.2f", *rule.Min, num)
-				if rule.CustomError != "" {
-					msg = rule.CustomError
-				}
-				if failFast {
-					return fail(key, msg)
-				}
-				errors[key] = msg
-			}
-			if rule.Max != nil && num > *rule.Max {
-				msg := fmt.Sprintf("Expected number <= %.2f but got: %.2f", *rule.Max, num)
-				if rule.CustomError != "" {
-					msg = rule.CustomError
-				}
-				if failFast {
-					return fail(key, msg)
-				}
-				errors[key] = msg
+	ctx := exprContext{env: known, rules: schema.Rules}
+	for _, c := range schema.Constraints {
+		if v.stopped {
+			break
+		}
+		if f, failed := checkConstraint(c, ctx); failed {
+			f.Position = v.firstPosition(f.Keys)
+			v.add(c.DisplayName(), f)
+		}
+	}
+
+	if opts.StrictMode && !v.stopped {
+		for _, key := range extraKeys {
+			if v.stopped {
+				break
 			}
+			v.result.ExtraKeys = append(v.result.ExtraKeys, key)
+			// The value of an undeclared key may be a secret, so it is not
+			// part of the finding.
+			v.add(key, newFinding(CodeExtraKey, "Key is not defined in schema", "", ""))
+		}
+	}
 
-		case "boolean":
-			lower := strings.ToLower(value)
-			if lower != "true" && lower != "false" {
-				msg := fmt.Sprintf("Expected boolean but got: %s", value)
-				if rule.CustomError != "" {
-					msg = rule.CustomError
-				}
-				if failFast {
-					return fail(key, msg)
-				}
-				errors[key] = msg
+	return v.result
+}
+
+// addFunc records a finding for the check with the given code.
+type addFunc func(code, msg, expected, actual string)
+
+// checkValue runs every check of rule against a value that is present and
+// returns the findings without a key.
+func checkValue(value string, rule SchemaRule, opts Options) []Finding {
+	var findings []Finding
+	add := func(code, msg, expected, actual string) {
+		findings = append(findings, newFinding(code, msg, expected, actual))
+	}
+
+	// Allowed values check
+	if len(rule.Allowed) > 0 {
+		valid := false
+		for _, allowed := range rule.Allowed {
+			if value == fmt.Sprintf("%v", allowed) {
+				valid = true
+				break
 			}
+		}
+		if !valid {
+			add(CodeNotAllowed,
+				fmt.Sprintf("Value '%s' is not allowed. Expected one of: %v", value, rule.Allowed),
+				fmt.Sprintf("one of %v", rule.Allowed), value)
+		}
+	}
 
-		default:
-			warnings[key] = fmt.Sprintf("Unknown type '%s' — skipping check", rule.Type)
+	// Disallowed values check
+	for _, disallowed := range rule.Disallowed {
+		if value == fmt.Sprintf("%v", disallowed) {
+			add(CodeDisallowed, fmt.Sprintf("Value '%s' is not allowed", value),
+				fmt.Sprintf("none of %v", rule.Disallowed), value)
+			break
 		}
 	}
 
-	if strictMode {
-		for key := range envMap {
-			if _, exists := schema[key]; !exists {
-				extraKeys = append(extraKeys, key)
+	// Type checks
+	switch rule.Type {
+	case "string":
+		if rule.Pattern != "" {
+			if matched, err := regexp.MatchString(rule.Pattern, value); err != nil {
+				add(CodeInvalidPattern, fmt.Sprintf("Invalid regex pattern: %s", rule.Pattern), "", rule.Pattern)
+			} else if !matched {
+				add(CodePatternMismatch, fmt.Sprintf("Value does not match pattern: %s", rule.Pattern), rule.Pattern, value)
 			}
 		}
+		if rule.Length != nil && len(value) != *rule.Length {
+			add(CodeLength,
+				fmt.Sprintf("Expected string of length [%v] but got: %s", *rule.Length, value),
+				fmt.Sprintf("length %d", *rule.Length), fmt.Sprintf("length %d", len(value)))
+		}
+		if rule.MaxLength != nil && len(value) > *rule.MaxLength {
+			add(CodeMaxLength,
+				fmt.Sprintf("Expected max length [%v] but got: %s", *rule.MaxLength, value),
+				fmt.Sprintf("length <= %d", *rule.MaxLength), fmt.Sprintf("length %d", len(value)))
+		}
+		if rule.MinLength != nil && len(value) < *rule.MinLength {
+			add(CodeMinLength,
+				fmt.Sprintf("Expected min length [%v] but got: %s", *rule.MinLength, value),
+				fmt.Sprintf("length >= %d", *rule.MinLength), fmt.Sprintf("length %d", len(value)))
+		}
 
-		if len(extraKeys) > 0 {
-			passed = false
+	case "number":
+		num, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			add(CodeNotNumber, fmt.Sprintf("Expected number but got: %s", value), "number", value)
+			break
 		}
-	}
+		checkRange("number", value, num, rule, add, parseFloat, func(f float64) string {
+			return fmt.Sprintf("%.2f", f)
+		})
+
+	case "boolean":
+		lower := strings.ToLower(value)
+		if lower != "true" && lower != "false" {
+			add(CodeNotBoolean, fmt.Sprintf("Expected boolean but got: %s", value), "boolean", value)
+		}
+
+	case "integer":
+		checkInteger(value, rule, add)
+
+	case "port":
+		checkPort(value, rule, add)
+
+	case "duration":
+		checkDuration(value, rule, add)
+
+	case "bytesize":
+		checkByteSize(value, rule, add)
+
+	case "email":
+		checkEmail(value, add)
+
+	case "hostname":
+		checkHostname(value, add)
+
+	case "ip":
+		checkIP(value, rule, add)
 
-	passed = len(errors) == 0 && passed
+	case "cidr":
+		checkCIDR(value, rule, add)
 
-	return ValidationResult{
-		Passed:    passed,
-		Errors:    errors,
-		Warnings:  warnings,
-		ExtraKeys: extraKeys,
+	case "url":
+		checkURL(value, rule, add)
+
+	case "base64":
+		checkBase64(value, rule, add)
+
+	case "hex":
+		checkHex(value, rule, add)
+
+	case "uuid":
+		checkUUID(value, rule, add)
+
+	case "jwt":
+		checkJWT(value, add)
+
+	case "list":
+		checkList(value, rule, opts, add)
+
+	case "json":
+		checkJSON(value, rule, add)
+
+	case "date":
+		checkDate(value, rule, add)
+
+	case "datetime":
+		checkDateTime(value, rule, add)
+
+	case "timezone":
+		checkTimezone(value, add)
+
+	case "path":
+		checkPath(value, rule, opts.BaseDir, add)
+
+	case "semver":
+		checkSemver(value, rule, add)
+
+	case "cron":
+		checkCron(value, rule, add)
+
+	default:
+		add(CodeUnknownType, fmt.Sprintf("Unknown type '%s' — skipping check", rule.Type), "", rule.Type)
 	}
+
+	return findings
 }
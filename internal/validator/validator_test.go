@@ -1,15 +1,16 @@
 package validator
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chidinma21/env-lint/internal/envfile"
+)
 
 func IntPtr(i int) *int {
 	return &i
 }
 
-func Float64Ptr(f float64) *float64 {
-	return &f
-}
-
 func TestValidateEnv(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -332,8 +333,8 @@ func TestValidateEnv(t *testing.T) {
 				"PORT": {
 					Type:     "number",
 					Required: true,
-					Min:      Float64Ptr(3000),
-					Max:      Float64Ptr(9999),
+					Min:      "3000",
+					Max:      "9999",
 				},
 			},
 			wantPass:  true,
@@ -349,8 +350,8 @@ func TestValidateEnv(t *testing.T) {
 				"PORT": {
 					Type:     "number",
 					Required: true,
-					Min:      Float64Ptr(3000),
-					Max:      Float64Ptr(9999),
+					Min:      "3000",
+					Max:      "9999",
 				},
 			},
 			wantPass: false,
@@ -368,8 +369,8 @@ func TestValidateEnv(t *testing.T) {
 				"PORT": {
 					Type:     "number",
 					Required: true,
-					Min:      Float64Ptr(3000),
-					Max:      Float64Ptr(9999),
+					Min:      "3000",
+					Max:      "9999",
 				},
 			},
 			wantPass: false,
@@ -387,7 +388,7 @@ func TestValidateEnv(t *testing.T) {
 				"PORT": {
 					Type:        "number",
 					Required:    true,
-					Min:         Float64Ptr(3000),
+					Min:         "3000",
 					CustomError: "Port error occurred",
 				},
 			},
@@ -421,3 +422,336 @@ func TestValidateEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePositions(t *testing.T) {
+	env := map[string]string{
+		"PORT":  "abc",
+		"DEBUG": "true",
+		"EXTRA": "1",
+	}
+	schema := map[string]SchemaRule{
+		"PORT":    {Type: "number", Required: true},
+		"DEBUG":   {Type: "boolean"},
+		"API_KEY": {Type: "string", Required: true},
+	}
+	positions := map[string]envfile.Position{
+		"PORT":  {File: ".env", Line: 1, Column: 1},
+		"DEBUG": {File: ".env", Line: 2, Column: 1},
+		"EXTRA": {File: ".env", Line: 3, Column: 1},
+	}
+
+	got := Validate(env, Schema{Rules: schema}, Options{StrictMode: true, Positions: positions})
+
+	want := map[string]envfile.Position{
+		"PORT":  positions["PORT"],
+		"EXTRA": positions["EXTRA"],
+	}
+	if len(got.Positions) != len(want) {
+		t.Errorf("Expected %d positions, got %d: %v", len(want), len(got.Positions), got.Positions)
+	}
+	for k, v := range want {
+		if got.Positions[k] != v {
+			t.Errorf("Expected position of %s to be %v, got %v", k, v, got.Positions[k])
+		}
+	}
+}
+
+func TestWithoutWarnings(t *testing.T) {
+	env := map[string]string{"PORT": "abc"}
+	schema := map[string]SchemaRule{
+		"PORT":  {Type: "number"},
+		"DEBUG": {Type: "boolean"},
+	}
+	positions := map[string]envfile.Position{"PORT": {File: ".env", Line: 1, Column: 1}}
+
+	got := Validate(env, Schema{Rules: schema}, Options{Positions: positions}).WithoutWarnings()
+	if len(got.Findings) != 1 || got.Findings[0].Key != "PORT" || got.Findings[0].Severity != SeverityError {
+		t.Errorf("Expected only the PORT error, got %+v", got.Findings)
+	}
+	if len(got.Warnings) != 0 || got.Errors["PORT"] == "" || got.Passed {
+		t.Errorf("Unexpected result: %+v", got)
+	}
+	if len(got.Positions) != 1 {
+		t.Errorf("Expected only the position of PORT, got %v", got.Positions)
+	}
+}
+
+func TestValidateExtraKeys(t *testing.T) {
+	env := map[string]string{"PORT": "8080", "STRIPE_SECRET": "sk_live_123"}
+	schema := map[string]SchemaRule{"PORT": {Type: "port"}}
+
+	got := ValidateEnv(env, schema, false, true)
+	if got.Passed {
+		t.Fatalf("Expected validation to fail in strict mode")
+	}
+	if len(got.Errors) != 0 {
+		t.Errorf("Expected extra keys to stay out of Errors, got %v", got.Errors)
+	}
+	f := got.FindingsFor("STRIPE_SECRET", SeverityError)
+	if len(f) != 1 || f[0].Code != CodeExtraKey || f[0].Actual != "" {
+		t.Errorf("Expected one extra-key finding without the value, got %+v", f)
+	}
+}
+
+func TestValidateMultipleFindings(t *testing.T) {
+	env := map[string]string{
+		"NAME": "AB",
+	}
+	schema := map[string]SchemaRule{
+		"NAME": {
+			Type:      "string",
+			Allowed:   []interface{}{"alpha", "bravo"},
+			Pattern:   "^[a-z]+$",
+			MinLength: IntPtr(5),
+		},
+	}
+
+	got := ValidateEnv(env, schema, false, false)
+	if got.Passed {
+		t.Fatalf("Expected validation to fail")
+	}
+
+	wantRules := []string{"allowed", "pattern", "minLength"}
+	wantCodes := []string{CodeNotAllowed, CodePatternMismatch, CodeMinLength}
+	errs := got.FindingsFor("NAME", SeverityError)
+	if len(errs) != len(wantRules) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(wantRules), len(errs), errs)
+	}
+	for i, rule := range wantRules {
+		if errs[i].Rule != rule || errs[i].Code != wantCodes[i] {
+			t.Errorf("Expected error %d to be %s (%s), got %s (%s)", i, wantCodes[i], rule, errs[i].Code, errs[i].Rule)
+		}
+		if errs[i].Actual == "" || errs[i].Expected == "" {
+			t.Errorf("Expected error %d to have expected and actual values, got %+v", i, errs[i])
+		}
+	}
+	if got.Errors["NAME"] != errs[0].Message {
+		t.Errorf("Expected Errors to hold the first message, got %s", got.Errors["NAME"])
+	}
+
+	got = ValidateEnv(env, schema, true, false)
+	if n := len(got.FindingsFor("NAME", SeverityError)); n != 1 {
+		t.Errorf("Expected fail-fast to stop after 1 error, got %d", n)
+	}
+}
+
+func TestValidateConditionalRequirements(t *testing.T) {
+	schema := map[string]SchemaRule{
+		"SMTP_PASSWORD": {Type: "string", RequiredIf: Condition{"MAIL_DRIVER": "smtp"}},
+		"TLS_CERT_PATH": {Type: "string", RequiredIf: Condition{"TLS_ENABLED": true, "ENV": []interface{}{"staging", "production"}}},
+		"SENTRY_DSN":    {Type: "url", RequiredUnless: Condition{"ENV": "development"}},
+		"MAIL_DRIVER":   {Type: "string", Default: "smtp"},
+		"TLS_ENABLED":   {Type: "boolean"},
+		"ENV":           {Type: "string"},
+	}
+
+	tests := []struct {
+		name      string
+		env       map[string]string
+		wantRules map[string]string
+	}{
+		{
+			name:      "Default value triggers requiredIf",
+			env:       map[string]string{"ENV": "development"},
+			wantRules: map[string]string{"SMTP_PASSWORD": "requiredIf"},
+		},
+		{
+			name: "Conditions not met",
+			env:  map[string]string{"ENV": "development", "MAIL_DRIVER": "log", "TLS_ENABLED": "true"},
+		},
+		{
+			name: "All conditions met",
+			env:  map[string]string{"ENV": "production", "MAIL_DRIVER": "log", "TLS_ENABLED": "true"},
+			wantRules: map[string]string{
+				"TLS_CERT_PATH": "requiredIf",
+				"SENTRY_DSN":    "requiredUnless",
+			},
+		},
+		{
+			name: "Condition values compared with the key type",
+			env:  map[string]string{"ENV": "staging", "MAIL_DRIVER": "log", "TLS_ENABLED": "True"},
+			wantRules: map[string]string{
+				"TLS_CERT_PATH": "requiredIf",
+				"SENTRY_DSN":    "requiredUnless",
+			},
+		},
+		{
+			name:      "Missing key in condition",
+			env:       map[string]string{"MAIL_DRIVER": "log", "TLS_ENABLED": "true"},
+			wantRules: map[string]string{"SENTRY_DSN": "requiredUnless"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateEnv(tt.env, schema, false, false)
+			if len(got.Errors) != len(tt.wantRules) {
+				t.Errorf("Expected errors for %v, got %v", tt.wantRules, got.Errors)
+			}
+			for key, rule := range tt.wantRules {
+				errs := got.FindingsFor(key, SeverityError)
+				if len(errs) != 1 || errs[0].Code != CodeMissingRequired || errs[0].Rule != rule {
+					t.Errorf("Expected %s to be missing by %s, got %+v", key, rule, errs)
+				}
+			}
+		})
+	}
+
+	got := ValidateEnv(map[string]string{"ENV": "production", "TLS_ENABLED": "true"}, schema, false, false)
+	want := "Missing key required when ENV is one of [staging production] and TLS_ENABLED=true"
+	if got.Errors["TLS_CERT_PATH"] != want {
+		t.Errorf("Expected message %q, got %q", want, got.Errors["TLS_CERT_PATH"])
+	}
+}
+
+func TestValidateGroups(t *testing.T) {
+	schema := Schema{
+		Rules: map[string]SchemaRule{
+			"DATABASE_URL": {Type: "url"},
+			"DB_HOST":      {Type: "hostname", Default: "localhost"},
+		},
+		Groups: []Group{
+			{Type: "oneOf", Keys: []GroupMember{{"DATABASE_URL"}, {"DB_HOST", "DB_USER", "DB_NAME"}}, Name: "database"},
+			{Type: "allOrNone", Keys: []GroupMember{{"AWS_ACCESS_KEY_ID"}, {"AWS_SECRET_ACCESS_KEY"}}},
+			{Type: "exclusive", Keys: []GroupMember{{"REDIS_URL"}, {"REDIS_SENTINELS"}}, Message: "Use either REDIS_URL or REDIS_SENTINELS"},
+			{Type: "anyOf", Keys: []GroupMember{{"LOG_FILE"}, {"LOG_SYSLOG"}}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		wantMsgs map[string]string
+	}{
+		{
+			name: "All groups satisfied",
+			env:  map[string]string{"DATABASE_URL": "postgres://db/app", "LOG_SYSLOG": "true"},
+		},
+		{
+			name: "Defaults and empty values do not count",
+			env:  map[string]string{"DB_USER": "", "AWS_ACCESS_KEY_ID": "AKIA", "REDIS_URL": "redis://r", "REDIS_SENTINELS": "s1", "LOG_FILE": ""},
+			wantMsgs: map[string]string{
+				"database": "Expected exactly one of DATABASE_URL, DB_HOST+DB_USER+DB_NAME but none is set",
+				"allOrNone(AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY)": "Expected all or none of AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY but AWS_SECRET_ACCESS_KEY is not set",
+				"exclusive(REDIS_URL, REDIS_SENTINELS)":               "Use either REDIS_URL or REDIS_SENTINELS",
+				"anyOf(LOG_FILE, LOG_SYSLOG)":                         "Expected at least one of LOG_FILE, LOG_SYSLOG but none is set",
+			},
+		},
+		{
+			name: "Both members of oneOf",
+			env:  map[string]string{"DATABASE_URL": "postgres://db/app", "DB_HOST": "db", "DB_USER": "app", "DB_NAME": "app", "LOG_FILE": "/var/log/app"},
+			wantMsgs: map[string]string{
+				"database": "Expected exactly one of DATABASE_URL, DB_HOST+DB_USER+DB_NAME but got: DATABASE_URL, DB_HOST+DB_USER+DB_NAME",
+			},
+		},
+		{
+			name: "Incomplete member of oneOf",
+			env:  map[string]string{"DB_HOST": "db", "LOG_FILE": "/var/log/app"},
+			wantMsgs: map[string]string{
+				"database": "Expected exactly one of DATABASE_URL, DB_HOST+DB_USER+DB_NAME but DB_HOST+DB_USER+DB_NAME (missing DB_USER, DB_NAME) is incomplete",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Validate(tt.env, schema, Options{})
+			msgs := make(map[string]string)
+			for _, f := range got.Findings {
+				if f.Severity == SeverityError {
+					msgs[f.Key] = f.Message
+				}
+			}
+			if !reflect.DeepEqual(msgs, tt.wantMsgs) && len(msgs)+len(tt.wantMsgs) > 0 {
+				t.Errorf("Expected errors %v, got %v", tt.wantMsgs, msgs)
+			}
+			if got.Passed != (len(tt.wantMsgs) == 0) || len(got.Errors) != 0 {
+				t.Errorf("Expected pass = %v and no key errors, got %v and %v", len(tt.wantMsgs) == 0, got.Passed, got.Errors)
+			}
+		})
+	}
+
+	got := Validate(map[string]string{"DATABASE_URL": "postgres://db/app", "DB_HOST": "db", "DB_USER": "app", "DB_NAME": "app"}, schema, Options{})
+	f := got.FindingsFor("database", SeverityError)
+	if len(f) != 1 || f[0].Code != CodeGroupOneOf || f[0].Rule != "oneOf" || !reflect.DeepEqual(f[0].Keys, []string{"DATABASE_URL", "DB_HOST", "DB_USER", "DB_NAME"}) {
+		t.Errorf("Unexpected group finding: %+v", f)
+	}
+}
+
+func TestValidateConstraints(t *testing.T) {
+	rules := map[string]SchemaRule{
+		"POOL_MIN":      {Type: "integer"},
+		"POOL_MAX":      {Type: "integer", Default: 10},
+		"READ_TIMEOUT":  {Type: "duration"},
+		"WRITE_TIMEOUT": {Type: "duration"},
+		"ADMIN_EMAILS":  {Type: "list", Items: &SchemaRule{Type: "email"}},
+		"ENV":           {Type: "string"},
+		"API_VERSION":   {Type: "semver"},
+		"CACHE_SIZE":    {Type: "bytesize"},
+	}
+
+	tests := []struct {
+		expr string
+		env  map[string]string
+		want string // expected code, "" when the constraint holds or is skipped
+	}{
+		{"POOL_MIN <= POOL_MAX", map[string]string{"POOL_MIN": "5"}, ""},
+		{"POOL_MIN <= POOL_MAX", map[string]string{"POOL_MIN": "50"}, CodeConstraintFailed},
+		{"READ_TIMEOUT < WRITE_TIMEOUT", map[string]string{"READ_TIMEOUT": "90s", "WRITE_TIMEOUT": "1m"}, CodeConstraintFailed},
+		{"READ_TIMEOUT < WRITE_TIMEOUT", map[string]string{"READ_TIMEOUT": "30s"}, ""},
+		{"READ_TIMEOUT < WRITE_TIMEOUT", map[string]string{"READ_TIMEOUT": "soon", "WRITE_TIMEOUT": "1m"}, ""},
+		{`len(ADMIN_EMAILS) > 0 if ENV == "production"`, map[string]string{"ENV": "production", "ADMIN_EMAILS": ""}, CodeConstraintFailed},
+		{`len(ADMIN_EMAILS) > 0 if ENV == "production"`, map[string]string{"ENV": "production"}, CodeConstraintFailed},
+		{`len(ADMIN_EMAILS) > 0 if ENV == "production"`, map[string]string{"ENV": "staging", "ADMIN_EMAILS": ""}, ""},
+		{`len(ADMIN_EMAILS) >= 2 if ENV == "production"`, map[string]string{"ENV": "production", "ADMIN_EMAILS": "a@x.io, b@x.io"}, ""},
+		{"READ_TIMEOUT <= 30s && CACHE_SIZE >= 64MiB", map[string]string{"READ_TIMEOUT": "10s", "CACHE_SIZE": "1GB"}, ""},
+		{`API_VERSION >= "1.10.0" || !(ENV != "dev")`, map[string]string{"API_VERSION": "1.9.0", "ENV": "prod"}, CodeConstraintFailed},
+		{`API_VERSION >= "1.10.0" || !(ENV != "dev")`, map[string]string{"API_VERSION": "1.9.0", "ENV": "dev"}, ""},
+		{"READ_TIMEOUT < ENV", map[string]string{"READ_TIMEOUT": "1s", "ENV": "dev"}, CodeConstraintError},
+		{"POOL_MIN < READ_TIMEOUT", map[string]string{"POOL_MIN": "5", "READ_TIMEOUT": "1s"}, CodeConstraintError},
+		{"CACHE_SIZE > READ_TIMEOUT", map[string]string{"CACHE_SIZE": "1GB", "READ_TIMEOUT": "1s"}, CodeConstraintError},
+		{"len(ADMIN_EMAILS) < READ_TIMEOUT", map[string]string{"ADMIN_EMAILS": "a@x.io", "READ_TIMEOUT": "1s"}, CodeConstraintError},
+		{"POOL_MIN < 5", map[string]string{"POOL_MIN": "3"}, ""},
+		{"READ_TIMEOUT < 5", map[string]string{"READ_TIMEOUT": "1s"}, CodeConstraintError},
+		{"POOL_MIN", map[string]string{"POOL_MIN": "5"}, CodeConstraintError},
+	}
+
+	for _, tt := range tests {
+		schema := Schema{Rules: rules, Constraints: []Constraint{{Expr: tt.expr}}}
+		got := Validate(tt.env, schema, Options{})
+
+		var code string
+		if f := got.FindingsFor(tt.expr, SeverityError); len(f) > 0 {
+			code = f[0].Code
+		} else if f := got.FindingsFor(tt.expr, SeverityWarning); len(f) > 0 {
+			code = f[0].Code
+		}
+		if code != tt.want {
+			t.Errorf("%s with %v: expected %q, got %q (%v)", tt.expr, tt.env, tt.want, code, got.Findings)
+		}
+	}
+
+	schema := Schema{Rules: rules, Constraints: []Constraint{{Expr: "POOL_MIN <= POOL_MAX", Name: "pool", Message: "POOL_MIN must not exceed POOL_MAX"}}}
+	got := Validate(map[string]string{"POOL_MIN": "20", "POOL_MAX": "8"}, schema, Options{})
+	f := got.FindingsFor("pool", SeverityError)
+	if len(f) != 1 || f[0].Message != "POOL_MIN must not exceed POOL_MAX" || f[0].Actual != "POOL_MIN=20, POOL_MAX=8" ||
+		!reflect.DeepEqual(f[0].Keys, []string{"POOL_MIN", "POOL_MAX"}) {
+		t.Errorf("Unexpected constraint finding: %+v", f)
+	}
+}
+
+func TestChecks(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, c := range Checks {
+		if seen[c.Code] {
+			t.Errorf("Duplicate check code %s", c.Code)
+		}
+		seen[c.Code] = true
+		if c.Rule == "" || c.Description == "" {
+			t.Errorf("Check %s is missing a rule or description", c.Code)
+		}
+		if c.Severity != SeverityError && c.Severity != SeverityWarning {
+			t.Errorf("Check %s has unknown severity %q", c.Code, c.Severity)
+		}
+	}
+}
@@ -11,11 +11,24 @@ func GuessType(val string) string {
 	if lower == "true" || lower == "false" {
 		return "boolean"
 	}
-	if _, err := strconv.Atoi(val); err == nil {
-		return "number"
+	if _, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return "integer"
 	}
 	if _, err := strconv.ParseFloat(val, 64); err == nil {
 		return "number"
 	}
 	return "string"
 }
+
+// GuessKeyType guesses the type of a .env entry from its key and value. Keys
+// named PORT or ending in _PORT that hold a valid port number are ports;
+// everything else falls back to GuessType.
+func GuessKeyType(key, val string) string {
+	upper := strings.ToUpper(key)
+	if upper == "PORT" || strings.HasSuffix(upper, "_PORT") {
+		if port, err := strconv.Atoi(val); err == nil && port >= 1 && port <= 65535 {
+			return "port"
+		}
+	}
+	return GuessType(val)
+}